@@ -0,0 +1,195 @@
+package url
+
+import (
+	"sort"
+	"strconv"
+)
+
+// array is the ordered hash backing ValueArray, keys are either int or string.
+type array struct {
+	// keys in insertion order
+	keys     []any
+	elements map[any]Value
+	// key used by the next append, one above the highest int key
+	next int
+}
+
+func newArray() *array {
+	return &array{elements: make(map[any]Value)}
+}
+
+// returns k as an int if it is a string holding a canonical integer, as PHP does.
+func arrayKey(k any) any {
+	if s, ok := k.(string); ok {
+		if i, err := strconv.Atoi(s); err == nil && strconv.Itoa(i) == s {
+			return i
+		}
+	}
+	return k
+}
+
+func (a *array) get(k any) (Value, bool) {
+	v, ok := a.elements[arrayKey(k)]
+	return v, ok
+}
+
+// stores v at k, a nil k appends v using the next int key. Returns the key used.
+func (a *array) set(k any, v Value) any {
+	if k == nil {
+		k = a.next
+	}
+	k = arrayKey(k)
+	if _, ok := a.elements[k]; !ok {
+		a.keys = append(a.keys, k)
+	}
+	a.elements[k] = v
+	if i, ok := k.(int); ok && i >= a.next {
+		a.next = i + 1
+	}
+	return k
+}
+
+func (a *array) delete(k any) {
+	k = arrayKey(k)
+	if _, ok := a.elements[k]; !ok {
+		return
+	}
+	delete(a.elements, k)
+	for i, key := range a.keys {
+		if key == k {
+			a.keys = append(a.keys[:i], a.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// returns the element of the ValueArray val at k, creating it if missing. A nil k appends a new element.
+func (val *item) arrayFor(k any) (Value, error) {
+	if !val.cast(ValueArray) {
+		return nil, ErrValueNotArray
+	}
+	a := val.value.(*array)
+	if k != nil {
+		if v, ok := a.get(k); ok {
+			return v, nil
+		}
+	}
+	v := newNilValue("")
+	v.key = pathKey(val.key, a.set(k, v))
+	return v, nil
+}
+
+// converts the ValueSlice or ValueMap val into a ValueArray, slice elements keep their index
+// and ValueNil gaps are dropped, map keys keep their insertion order.
+func (val *item) toArray() {
+	list := entries(val, OrderInsertion)
+	val.valueType, val.value = ValueNil, ""
+	val.to(ValueArray)
+	a := val.value.(*array)
+	for _, e := range list {
+		if _, ok := e.key.(int); ok && e.value.IsNil() {
+			continue
+		}
+		k := a.set(e.key, e.value)
+		rekey(e.value.(*item), pathKey(val.key, k))
+	}
+}
+
+func (val *item) IntKeys() []int {
+	switch val.valueType {
+	case ValueSlice:
+		keys := make([]int, val.Len())
+		for i := range keys {
+			keys[i] = i
+		}
+		return keys
+	case ValueArray:
+		var keys []int
+		for _, k := range val.value.(*array).keys {
+			if i, ok := k.(int); ok {
+				keys = append(keys, i)
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+func (val *item) StringKeys() []string {
+	switch val.valueType {
+	case ValueMap:
+		return append([]string{}, val.value.(*orderedMap).keys...)
+	case ValueArray:
+		var keys []string
+		for _, k := range val.value.(*array).keys {
+			if s, ok := k.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+func (val *item) ToSlice() []Value {
+	if s, ok := val.Slice(); ok {
+		return s
+	}
+	list := entries(val, OrderInsertion)
+	if list == nil {
+		return nil
+	}
+	out := make([]Value, len(list))
+	for i, e := range list {
+		out[i] = e.value
+	}
+	return out
+}
+
+func (val *item) ToMap() map[string]Value {
+	if m, ok := val.Map(); ok {
+		return m
+	}
+	list := entries(val, OrderInsertion)
+	if list == nil {
+		return nil
+	}
+	out := make(map[string]Value, len(list))
+	for _, e := range list {
+		if i, ok := e.key.(int); ok {
+			out[strconv.Itoa(i)] = e.value
+		} else {
+			out[e.key.(string)] = e.value
+		}
+	}
+	return out
+}
+
+// reports whether the int keys of the ValueArray val are 0 to Len()-1 in order, as a PHP list.
+func isList(val Value) bool {
+	a, ok := val.(*item).value.(*array)
+	if !ok {
+		return false
+	}
+	for i, k := range a.keys {
+		if k != any(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// sorts the keys of a ValueArray, int keys first.
+func sortArrayKeys(keys []any) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		ki, iok := keys[i].(int)
+		kj, jok := keys[j].(int)
+		switch {
+		case iok && jok:
+			return ki < kj
+		case iok != jok:
+			return iok
+		}
+		return keys[i].(string) < keys[j].(string)
+	})
+}
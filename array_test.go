@@ -0,0 +1,129 @@
+package url_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestValueArray(t *testing.T) {
+	opts := URL.ParseOptions{MixedKeys: true}
+	for _, raw := range []string{"input[0]=a&input[key]=b&input[2]=c", "input[key]=b&input[0]=a&input[2]=c"} {
+		mapV, err := URL.ParseQueryWithOptions(raw, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := mapV.GetValue("input")
+		if !v.Is(URL.ValueArray) || v.Len() != 3 {
+			t.Fatalf("%s: expected a ValueArray of 3 elements, found %s of %d", raw, v.Type(), v.Len())
+		}
+		if s := mapV.GetString("input", 2); s != "c" {
+			t.Errorf("%s: input[2]: expected c found %q", raw, s)
+		}
+		if s := mapV.GetString("input", "key"); s != "b" {
+			t.Errorf("%s: input[key]: expected b found %q", raw, s)
+		}
+	}
+
+	mapV, err := URL.ParseQueryWithOptions("input[0]=a&input[key]=b&input[2]=c&input[]=d&list[]=x&list[]=y", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _ := mapV.GetValue("input")
+	if keys := v.IntKeys(); !reflect.DeepEqual(keys, []int{0, 2, 3}) {
+		t.Errorf("IntKeys: unexpected %v", keys)
+	}
+	if keys := v.StringKeys(); !reflect.DeepEqual(keys, []string{"key"}) {
+		t.Errorf("StringKeys: unexpected %v", keys)
+	}
+	if s := mapV.GetStrings("input"); !reflect.DeepEqual(s, []string{"a", "b", "c", "d"}) {
+		t.Errorf("ToSlice: unexpected %v", s)
+	}
+	m := v.ToMap()
+	if s, _ := m["3"].String(); len(m) != 4 || s != "d" || m["3"].Key() != "input[3]" {
+		t.Errorf("ToMap: unexpected %v", m)
+	}
+	if _, ok := v.Slice(); ok {
+		t.Error("Slice: expected ok to be false for ValueArray")
+	}
+	list, _ := mapV.GetValue("list")
+	if !list.Is(URL.ValueSlice) || !reflect.DeepEqual(list.IntKeys(), []int{0, 1}) {
+		t.Errorf("list: expected a ValueSlice, found %s", list.Type())
+	}
+
+	t.Run("negative index", func(t *testing.T) {
+		opts := URL.ParseOptions{MixedKeys: true, NegativeIndex: URL.NegativeIndexFromEnd, Strict: true}
+		mapV, err := URL.ParseQueryWithOptions("a[x]=1&a[0]=2&a[5]=3&a[-2]=z", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, _ := mapV.GetValue("a")
+		if s := mapV.GetStrings("a"); !reflect.DeepEqual(s, []string{"1", "z", "3"}) || !reflect.DeepEqual(a.IntKeys(), []int{0, 5}) {
+			t.Errorf("expected [1 z 3] with keys [0 5], found %v with keys %v", s, a.IntKeys())
+		}
+		_, err = URL.ParseQueryWithOptions("a[x]=1&a[0]=2&a[-2]=z", opts)
+		var errs *URL.ParseErrors
+		if !errors.As(err, &errs) || len(errs.Errors) != 1 || errs.Errors[0].Reason != URL.ReasonInvalidIndex {
+			t.Errorf("expected an invalid index, found %v", err)
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		if s := v.Encode(URL.EncodeOptions{Order: URL.OrderInsertion}); s != "input%5B0%5D=a&input%5Bkey%5D=b&input%5B2%5D=c&input%5B3%5D=d" {
+			t.Errorf("Encode: unexpected %s", s)
+		}
+		if s := v.Encode(URL.EncodeOptions{}); s != "input%5B0%5D=a&input%5B2%5D=c&input%5B3%5D=d&input%5Bkey%5D=b" {
+			t.Errorf("Encode sorted: unexpected %s", s)
+		}
+		b, err := v.MarshalJSON()
+		if err != nil || string(b) != `{"0":"a","key":"b","2":"c","3":"d"}` {
+			t.Errorf("MarshalJSON: unexpected %s (%v)", b, err)
+		}
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		mapV, _ := URL.ParseQueryWithOptions("a[0]=x&a[k]=y&a[5]=z", opts)
+		if err := mapV.Delete("a", 0); err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("a", 5); s != "z" {
+			t.Errorf("a[5]: expected keys not to be renumbered, found %q", s)
+		}
+		if err := mapV.Append([]any{"a"}, "w"); err != nil {
+			t.Fatal(err)
+		}
+		if w, _ := mapV.GetValue("a", 6); w.Key() != "a[6]" {
+			t.Errorf("Append: expected a[6] found %s", w.Key())
+		}
+		if err := mapV.Set("n", "a", "sub", "x"); err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("a", "sub", "x"); s != "n" {
+			t.Errorf("Set: expected n found %q", s)
+		}
+		if _, err := mapV.GetValue("a", 0); !errors.Is(err, URL.ErrKeyNotFound) {
+			t.Errorf("expected ErrKeyNotFound found %v", err)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var form struct {
+			Input map[string]string `url:"input"`
+			List  []string          `url:"list"`
+		}
+		if err := URL.Unmarshal(mapV, &form); err != nil {
+			t.Fatal(err)
+		}
+		if form.Input["key"] != "b" || form.Input["0"] != "a" || len(form.List) != 2 {
+			t.Errorf("unexpected %+v", form)
+		}
+		var values struct {
+			Input []string `url:"input"`
+		}
+		if err := URL.Unmarshal(mapV, &values); err != nil || !reflect.DeepEqual(values.Input, []string{"a", "b", "c", "d"}) {
+			t.Errorf("unexpected %v (%v)", values.Input, err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package url
+
+// NewNil returns a ValueNil.
+func NewNil() Value {
+	return newNilValue("")
+}
+
+// NewString returns a ValueString holding s.
+func NewString(s string) Value {
+	v := newNilValue("")
+	v.to(ValueString).setValue(s)
+	return v
+}
+
+// NewMap returns an empty ValueMap, use Set() to add elements.
+//
+//	m := url.NewMap()
+//	m.Set("John", "name")
+//	m.Set(url.NewSlice(url.NewString("a"), url.NewString("b")), "tags")
+func NewMap() Value {
+	return newNilValue("").to(ValueMap)
+}
+
+// NewSlice returns a ValueSlice holding a copy of elements.
+func NewSlice(elements ...Value) Value {
+	v := newNilValue("")
+	v.to(ValueSlice)
+	slice := v.value.(*[]Value)
+	for i, e := range elements {
+		*slice = append(*slice, copyValue(e, elementKey("", i)))
+	}
+	return v
+}
+
+// Builder builds a Map with a fluent API.
+//
+//	m, err := url.NewBuilder().
+//		Set("John", "name").
+//		Set("Rome", "address", "city").
+//		Append([]any{"tags"}, "a", "b").
+//		Build()
+//
+// Once an operation fails the following ones are ignored, Build() returns the first error.
+type Builder struct {
+	root Value
+	err  error
+}
+
+// NewBuilder returns a Builder holding an empty Map.
+func NewBuilder() *Builder {
+	return &Builder{root: NewMap()}
+}
+
+// Set stores value at path, see Map.Set().
+func (b *Builder) Set(value any, path ...any) *Builder {
+	if b.err == nil {
+		b.err = b.root.Set(value, path...)
+	}
+	return b
+}
+
+// Append appends values to the ValueSlice at path, see Map.Append().
+func (b *Builder) Append(path []any, values ...string) *Builder {
+	if b.err == nil {
+		b.err = b.root.Append(path, values...)
+	}
+	return b
+}
+
+// Insert inserts values in the ValueSlice at path, see Map.Insert().
+func (b *Builder) Insert(path []any, index int, values ...string) *Builder {
+	if b.err == nil {
+		b.err = b.root.Insert(path, index, values...)
+	}
+	return b
+}
+
+// Delete removes the element at path, see Map.Delete().
+func (b *Builder) Delete(path ...any) *Builder {
+	if b.err == nil {
+		b.err = b.root.Delete(path...)
+	}
+	return b
+}
+
+// Build returns the Map, or the first error returned by the Builder operations.
+func (b *Builder) Build() (Map, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.root, nil
+}
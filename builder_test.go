@@ -0,0 +1,61 @@
+package url_test
+
+import (
+	"errors"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestConstructors(t *testing.T) {
+	if v := URL.NewNil(); !v.IsNil() {
+		t.Errorf("NewNil: expected ValueNil found %s", v.Type())
+	}
+	if s, ok := URL.NewString("a").String(); !ok || s != "a" {
+		t.Errorf("NewString: expected a found %q", s)
+	}
+	slice := URL.NewSlice(URL.NewString("a"), URL.NewNil(), URL.NewSlice(URL.NewString("b")))
+	if !slice.Is(URL.ValueSlice) || slice.Len() != 3 {
+		t.Fatalf("NewSlice: expected 3 elements found %d", slice.Len())
+	}
+	if s := slice.GetString(2, 0); s != "b" {
+		t.Errorf("NewSlice: expected b found %q", s)
+	}
+
+	m := URL.NewMap()
+	if !m.Is(URL.ValueMap) || m.Len() != 0 {
+		t.Fatal("NewMap: expected an empty ValueMap")
+	}
+	if err := m.Set(slice, "list"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.GetValue("list", 2, 0)
+	if err != nil || v.Key() != "list[2][0]" {
+		t.Errorf("list[2][0]: unexpected %v (%v)", v, err)
+	}
+	if b, _ := m.MarshalJSON(); string(b) != `{"list":["a",null,["b"]]}` {
+		t.Errorf("unexpected %s", b)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	m, err := URL.NewBuilder().
+		Set("John", "name").
+		Set("Rome", "address", "city").
+		Append([]any{"tags"}, "b", "c").
+		Insert([]any{"tags"}, 0, "a").
+		Set("tmp", "tmp").
+		Delete("tmp").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := m.Encode(URL.EncodeOptions{IndexStyle: URL.IndexEmpty}); e != "address%5Bcity%5D=Rome&name=John&tags%5B%5D=a&tags%5B%5D=b&tags%5B%5D=c" {
+		t.Errorf("unexpected %s", e)
+	}
+
+	_, err = URL.NewBuilder().Set("x", "a").Set("y", "a", "b").Set("z", "c").Build()
+	if !errors.Is(err, URL.ErrValueNotMap) {
+		t.Errorf("expected ErrValueNotMap found %v", err)
+	}
+}
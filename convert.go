@@ -0,0 +1,110 @@
+package url
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseBool parses s following HTML checkbox semantics.
+//
+// "1", "t", "true", "on" and "yes" are true, "", "0", "f", "false", "off" and "no" are false, case is ignored.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "t", "true", "on", "yes":
+		return true, nil
+	case "", "0", "f", "false", "off", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+// converters registered with RegisterConverter, map[reflect.Type]func(string) (any, error)
+var converters sync.Map
+
+// RegisterConverter registers the function converting strings into T.
+//
+// Registered converters take precedence over the built-in conversions and are used by
+// Get, GetSlice, Convert and Unmarshal. Registering a converter for a type already registered replaces it.
+//
+//	url.RegisterConverter(func(s string) (uuid.UUID, error) { return uuid.Parse(s) })
+//	id, err := url.Get[uuid.UUID](mapV, "user", "id")
+func RegisterConverter[T any](convert func(s string) (T, error)) {
+	converters.Store(reflect.TypeOf((*T)(nil)).Elem(), func(s string) (any, error) {
+		return convert(s)
+	})
+}
+
+// Convert converts s into T.
+//
+// Registered converters are used first, then encoding.TextUnmarshaler, time.Duration, strings,
+// booleans (HTML checkbox semantics, see Map.GetBool()) and numeric kinds are supported.
+func Convert[T any](s string) (out T, err error) {
+	err = convertString(s, reflect.ValueOf(&out).Elem())
+	return out, err
+}
+
+// convertString stores s into rv, converting it to the rv type, see Convert().
+func convertString(s string, rv reflect.Value) error {
+	if c, ok := converters.Load(rv.Type()); ok {
+		v, err := c.(func(string) (any, error))(s)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package url_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+type testMoney struct {
+	cents int64
+}
+
+type testLevel int
+
+func TestConverters(t *testing.T) {
+	URL.RegisterConverter(func(s string) (testMoney, error) {
+		var units, cents int64
+		if _, err := fmt.Sscanf(s, "%d.%02d", &units, &cents); err != nil {
+			return testMoney{}, err
+		}
+		return testMoney{units*100 + cents}, nil
+	})
+	URL.RegisterConverter(func(s string) (testLevel, error) {
+		switch strings.ToLower(s) {
+		case "low":
+			return 1, nil
+		case "high":
+			return 2, nil
+		}
+		return 0, fmt.Errorf("unknown level %q", s)
+	})
+
+	mapV, err := URL.ParseQuery("price=12.34&level=high&ip=127.0.0.1&n[]=1&n[]=2&n[3]=4&prices[]=1.00&prices[]=2.50&bad=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err := URL.Get[testMoney](mapV, "price"); err != nil || m.cents != 1234 {
+		t.Errorf("Get[testMoney]: expected 1234 found %d (%v)", m.cents, err)
+	}
+	if l, err := URL.Get[testLevel](mapV, "level"); err != nil || l != 2 {
+		t.Errorf("Get[testLevel]: expected 2 found %d (%v)", l, err)
+	}
+	if ip, err := URL.Get[net.IP](mapV, "ip"); err != nil || !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("Get[net.IP]: unexpected %v (%v)", ip, err)
+	}
+	if s, err := URL.Get[string](mapV, "level"); err != nil || s != "high" {
+		t.Errorf("Get[string]: expected high found %q (%v)", s, err)
+	}
+	if n, err := URL.GetSlice[int](mapV, "n"); err != nil || fmt.Sprint(n) != "[1 2 0 4]" {
+		t.Errorf("GetSlice[int]: expected [1 2 0 4] found %v (%v)", n, err)
+	}
+	if p, err := URL.GetSlice[testMoney](mapV, "prices"); err != nil || len(p) != 2 || p[1].cents != 250 {
+		t.Errorf("GetSlice[testMoney]: unexpected %v (%v)", p, err)
+	}
+	if p, err := URL.GetSlice[testLevel](mapV, "level"); err != nil || len(p) != 1 || p[0] != 2 {
+		t.Errorf("GetSlice[testLevel]: unexpected %v (%v)", p, err)
+	}
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var out struct {
+			Price testMoney `url:"price"`
+			Level testLevel `url:"level"`
+		}
+		if err := URL.Unmarshal(mapV, &out); err != nil || out.Price.cents != 1234 || out.Level != 2 {
+			t.Errorf("unexpected %+v (%v)", out, err)
+		}
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		if l, err := URL.Convert[testLevel]("LOW"); err != nil || l != 1 {
+			t.Errorf("Convert[testLevel]: expected 1 found %d (%v)", l, err)
+		}
+		if _, err := URL.Convert[chan int]("x"); err == nil {
+			t.Error("Convert[chan int]: expected an error")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var tests = []struct {
+			err  error
+			path string
+		}{
+			{second(URL.Get[testLevel](mapV, "bad")), "bad"},
+			{second(URL.Get[int](mapV, "n")), "n"},
+			{second(URL.Get[int](mapV, "missing")), "missing"},
+			{second(URL.GetSlice[testLevel](mapV, "n")), "n[0]"},
+			{second(URL.Get[int](nil, "a", 0)), "a[0]"},
+			{second(URL.GetSlice[int](nil, "n")), "n"},
+		}
+		for _, test := range tests {
+			var perr *URL.PathError
+			if !errors.As(test.err, &perr) || perr.Path != test.path {
+				t.Errorf("expected *PathError at %s found %v", test.path, test.err)
+			}
+		}
+		if _, err := URL.Get[int](URL.FromContext(context.Background()), "a"); !errors.Is(err, URL.ErrNilMap) {
+			t.Errorf("expected ErrNilMap found %v", err)
+		}
+	})
+	t.Run("custom map", func(t *testing.T) {
+		m := testMap{mapV}
+		if n, err := URL.GetSlice[int](m, "n"); err != nil || fmt.Sprint(n) != "[1 2 0 4]" {
+			t.Errorf("expected [1 2 0 4] found %v, %v", n, err)
+		}
+		if ip, err := URL.Get[net.IP](m, "ip"); err != nil || !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+			t.Errorf("expected 127.0.0.1 found %v, %v", ip, err)
+		}
+		if _, err := URL.GetSlice[int](m, "missing"); err == nil {
+			t.Error("expected an error for a missing key")
+		}
+	})
+}
+
+// testMap is a Map implemented outside the package, its Values are wrapped as well.
+type testMap struct {
+	URL.Map
+}
+
+func (m testMap) GetValue(path ...any) (URL.Value, error) {
+	v, err := m.Map.GetValue(path...)
+	if err != nil {
+		return nil, err
+	}
+	return testValue{v}, nil
+}
+
+type testValue struct {
+	URL.Value
+}
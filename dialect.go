@@ -0,0 +1,181 @@
+package url
+
+import (
+	"strings"
+)
+
+// Dialect describes how a web framework serializes nested forms, set ParseOptions.Dialect to parse
+// keys and values the way the framework does.
+//
+// The package provides DialectPHP, DialectRack, DialectQS and DialectSchema, custom dialects can be
+// implemented to support other conventions.
+type Dialect interface {
+	// SplitKey splits a raw key into its root name and nested keys, "a[b][0]" is split into "a" and ["b", "0"].
+	// An empty nested key appends to a slice.
+	SplitKey(key string) (root string, nestedKeys []string, err error)
+	// SplitValue splits a raw value into the values it holds, most dialects return []string{value}.
+	SplitValue(value string) []string
+	// Rules returns the parser behaviour expected by the dialect.
+	Rules() DialectRules
+}
+
+// DialectRules defines the parser behaviour expected by a Dialect.
+//
+// Duplicates, NegativeIndex and NonCanonicalIndex are used when the matching ParseOptions field is left unset,
+// any other value set by the caller, including DuplicateDefault or NegativeIndexReject, takes precedence.
+type DialectRules struct {
+	// Duplicates defines how keys submitted more than once are handled.
+	Duplicates DuplicatePolicy
+	// NegativeIndex defines how negative indices are handled.
+	NegativeIndex NegativeIndexPolicy
+	// NonCanonicalIndex defines how non-canonical indices are handled.
+	NonCanonicalIndex NonCanonicalIndexPolicy
+	// NumericKeys handles every nested key as a map key, "a[0]" is the key "0" of the map "a".
+	NumericKeys bool
+	// MergeAppend stores "a[][x]=1&a[][y]=2" into the same map, a new element is appended only when
+	// the last element already holds the key, "a[][x]=1&a[][x]=2" results in two maps.
+	MergeAppend bool
+	// CompactSlices removes the ValueNil gaps from slices once parsing is done, "a[1]=b&a[15]=c" results in [b, c].
+	CompactSlices bool
+	// ArrayLimit is the highest index handled as a slice index, higher indices are map keys. Zero means no limit.
+	ArrayLimit int
+	// Arrays builds every container as a ValueArray, "a[5]=x&a[]=y" results in [5 => x, 6 => y].
+	Arrays bool
+}
+
+var (
+	// DialectPHP parses keys as PHP parse_str() and $_GET/$_POST do.
+	//
+	// Spaces and dots in root names are converted to "_", leading spaces are removed and an unmatched "[" becomes "_",
+	// "my value.x=1" is the key "my_value_x". Nested containers are ValueArray, as PHP arrays, the last value
+	// of a repeated key wins, negative and non-canonical indices are string keys.
+	DialectPHP Dialect = phpDialect{}
+	// DialectRack parses keys as Rack and Rails do.
+	//
+	// Nested keys are map keys, "a[0]" is the key "0" of the map "a", only "a[]" builds slices.
+	// "a[][x]=1&a[][y]=2" results in [{x: 1, y: 2}], the last value of a repeated key wins.
+	DialectRack Dialect = rackDialect{}
+	// DialectQS parses keys as the Node.js qs package does with the allowDots and comma options.
+	//
+	// "a.b=c" is the same as "a[b]=c", "a=b,c" results in [b, c], repeated keys are combined into a slice,
+	// slices are compacted and indices above 20 are map keys.
+	DialectQS Dialect = qsDialect{}
+	// DialectSchema parses keys as the Go gorilla/schema package does.
+	//
+	// Keys are dot separated, "phones.0.label" is the key "label" of the first element of "phones",
+	// repeated keys are combined into a slice.
+	DialectSchema Dialect = schemaDialect{}
+)
+
+type phpDialect struct{}
+
+func (phpDialect) SplitKey(key string) (root string, nestedKeys []string, err error) {
+	key = strings.TrimLeft(key, " ")
+	open := strings.IndexByte(key, '[')
+	if open != -1 && !strings.Contains(key[open:], "]") {
+		// the unmatched bracket is part of the root name
+		key = key[:open] + "_" + key[open+1:]
+		open = -1
+	}
+	root = key
+	if open != -1 {
+		root = key[:open]
+		if _, nestedKeys, err = getParseKey("_" + key[open:]); err != nil {
+			return "", nil, err
+		}
+	}
+	root = strings.NewReplacer(" ", "_", ".", "_").Replace(root)
+	return root, nestedKeys, nil
+}
+
+func (phpDialect) SplitValue(value string) []string {
+	return []string{value}
+}
+
+func (phpDialect) Rules() DialectRules {
+	return DialectRules{
+		Duplicates:        DuplicateLast,
+		NegativeIndex:     NegativeIndexAsKey,
+		NonCanonicalIndex: NonCanonicalIndexAsKey,
+		Arrays:            true,
+	}
+}
+
+type rackDialect struct{}
+
+func (rackDialect) SplitKey(key string) (root string, nestedKeys []string, err error) {
+	return getParseKey(key)
+}
+
+func (rackDialect) SplitValue(value string) []string {
+	return []string{value}
+}
+
+func (rackDialect) Rules() DialectRules {
+	return DialectRules{
+		Duplicates:  DuplicateLast,
+		NumericKeys: true,
+		MergeAppend: true,
+	}
+}
+
+type qsDialect struct{}
+
+func (qsDialect) SplitKey(key string) (root string, nestedKeys []string, err error) {
+	// allowDots, "a.b[c]" is the same as "a[b][c]"
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] != '.' {
+			b.WriteByte(key[i])
+			continue
+		}
+		end := i + 1
+		for end < len(key) && key[end] != '.' && key[end] != '[' {
+			end++
+		}
+		if end == i+1 {
+			b.WriteByte('.')
+			continue
+		}
+		b.WriteString("[" + key[i+1:end] + "]")
+		i = end - 1
+	}
+	return getParseKey(b.String())
+}
+
+func (qsDialect) SplitValue(value string) []string {
+	return strings.Split(value, ",")
+}
+
+func (qsDialect) Rules() DialectRules {
+	return DialectRules{
+		Duplicates:        DuplicatePromote,
+		NegativeIndex:     NegativeIndexAsKey,
+		NonCanonicalIndex: NonCanonicalIndexAsKey,
+		CompactSlices:     true,
+		ArrayLimit:        20,
+	}
+}
+
+type schemaDialect struct{}
+
+func (schemaDialect) SplitKey(key string) (root string, nestedKeys []string, err error) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts {
+		if part == "" {
+			return "", nil, ErrMalformedKey
+		}
+	}
+	return parts[0], parts[1:], nil
+}
+
+func (schemaDialect) SplitValue(value string) []string {
+	return []string{value}
+}
+
+func (schemaDialect) Rules() DialectRules {
+	return DialectRules{
+		Duplicates:        DuplicatePromote,
+		NonCanonicalIndex: NonCanonicalIndexNormalize,
+	}
+}
@@ -0,0 +1,149 @@
+package url_test
+
+import (
+	"net/url"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+type dialectTest struct {
+	raw    string
+	expect string // JSON
+}
+
+func testDialect(t *testing.T, dialect URL.Dialect, tests []dialectTest) {
+	t.Helper()
+	for _, test := range tests {
+		mapV, err := URL.ParseQueryWithOptions(test.raw, URL.ParseOptions{Dialect: dialect})
+		if err != nil {
+			t.Errorf("%s: %v", test.raw, err)
+			continue
+		}
+		expect, err := URL.FromJSON([]byte(test.expect))
+		if err != nil {
+			t.Fatalf("%s: %v", test.expect, err)
+		}
+		found, _ := mapV.MarshalJSON()
+		expectJSON, _ := expect.MarshalJSON()
+		if string(found) != string(expectJSON) {
+			t.Errorf("%s: expected %s found %s", test.raw, expectJSON, found)
+		}
+	}
+}
+
+func TestDialectPHP(t *testing.T) {
+	// https://www.php.net/manual/en/function.parse-str.php
+	// https://www.php.net/manual/en/language.variables.external.php
+	testDialect(t, URL.DialectPHP, []dialectTest{
+		{"first=value&arr[]=foo+bar&arr[]=baz", `{"first":"value","arr":["foo bar","baz"]}`},
+		{"My Value=Something", `{"My_Value":"Something"}`},
+		{"a.b=1&a b=2& c=3", `{"a_b":"2","c":"3"}`},
+		{"a[b.c]=1&a[b c]=2", `{"a":{"b.c":"1","b c":"2"}}`},
+		{"a[b=1", `{"a_b":"1"}`},
+		{"a=1&a=2", `{"a":"2"}`},
+		{"a[01]=x&a[-1]=y", `{"a":{"01":"x","-1":"y"}}`},
+		{"a[x][y]=1&a[x][z]=2", `{"a":{"x":{"y":"1","z":"2"}}}`},
+		{"a[5]=x&a[]=y", `{"a":{"5":"x","6":"y"}}`},
+		{"a[0]=a&a[key]=b&a[2]=c", `{"a":{"0":"a","key":"b","2":"c"}}`},
+		{"a[]=x&a[1]=y", `{"a":["x","y"]}`},
+	})
+
+	mapV, err := URL.ParseQueryWithOptions("a[1]=x&a[b]=y", URL.ParseOptions{Dialect: URL.DialectPHP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := mapV.GetValue("a")
+	if !a.Is(URL.ValueArray) {
+		t.Errorf("expected ValueArray found %s", a.Type())
+	}
+}
+
+func TestDialectRack(t *testing.T) {
+	// https://github.com/rack/rack/blob/main/test/spec_utils.rb
+	testDialect(t, URL.DialectRack, []dialectTest{
+		{"foo=bar&foo=quux", `{"foo":"quux"}`},
+		{"foo[]=bar&foo[]=quux", `{"foo":["bar","quux"]}`},
+		{"foo[0]=bar&foo[1]=quux", `{"foo":{"0":"bar","1":"quux"}}`},
+		{"foo[][bar]=1&foo[][baz]=2", `{"foo":[{"bar":"1","baz":"2"}]}`},
+		{"foo[][bar]=1&foo[][bar]=2", `{"foo":[{"bar":"1"},{"bar":"2"}]}`},
+		{"x[y][][z]=1&x[y][][w]=2", `{"x":{"y":[{"z":"1","w":"2"}]}}`},
+		{"x[y][][z]=1&x[y][][z]=2", `{"x":{"y":[{"z":"1"},{"z":"2"}]}}`},
+		{"x[y][][v][w]=1&x[y][][v][u]=2", `{"x":{"y":[{"v":{"w":"1","u":"2"}}]}}`},
+		{"x[y][][z][]=1&x[y][][z][]=2", `{"x":{"y":[{"z":["1","2"]}]}}`},
+		{"x[][a]=1&x[]=2&x[][a]=3", `{"x":[{"a":"1"},"2",{"a":"3"}]}`},
+	})
+}
+
+func TestDialectQS(t *testing.T) {
+	// https://github.com/ljharb/qs#parsing-objects
+	// https://github.com/ljharb/qs#parsing-arrays
+	testDialect(t, URL.DialectQS, []dialectTest{
+		{"a.b=c", `{"a":{"b":"c"}}`},
+		{"a.b.c=d&a[e]=f", `{"a":{"b":{"c":"d"},"e":"f"}}`},
+		{"a[]=b&a[]=c", `{"a":["b","c"]}`},
+		{"a[1]=c&a[0]=b", `{"a":["b","c"]}`},
+		{"a[1]=b&a[15]=c", `{"a":["b","c"]}`},
+		{"a[100]=b", `{"a":{"100":"b"}}`},
+		{"a[20]=b", `{"a":["b"]}`},
+		{"a=b&a=c", `{"a":["b","c"]}`},
+		{"a=b,c", `{"a":["b","c"]}`},
+		{"a[][b]=c", `{"a":[{"b":"c"}]}`},
+		{"a[-1]=b", `{"a":{"-1":"b"}}`},
+	})
+}
+
+func TestDialectSchema(t *testing.T) {
+	// https://github.com/gorilla/schema#example
+	testDialect(t, URL.DialectSchema, []dialectTest{
+		{"Name=Jane&Phones.0.Label=home&Phones.0.Number=1&Phones.1.Label=work", `{"Name":"Jane","Phones":[{"Label":"home","Number":"1"},{"Label":"work"}]}`},
+		{"Tags=a&Tags=b", `{"Tags":["a","b"]}`},
+		{"a[0]=x", `{"a[0]":"x"}`},
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		mapV, err := URL.ParseQueryWithOptions("a..b=1&c=2", URL.ParseOptions{Dialect: URL.DialectSchema, Strict: true})
+		if err == nil || mapV.GetString("c") != "2" {
+			t.Errorf("expected a..b to be reported, found %v", err)
+		}
+	})
+}
+
+func TestDialectOptions(t *testing.T) {
+	// explicit options take precedence over the dialect rules
+	mapV, err := URL.ParseQueryWithOptions("a=1&a=2", URL.ParseOptions{Dialect: URL.DialectQS, Duplicates: URL.DuplicateFirst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := mapV.GetString("a"); s != "1" {
+		t.Errorf("expected 1 found %q", s)
+	}
+
+	// policies equal to the defaults take precedence as well
+	mapV, err = URL.ParseQueryWithOptions("a=1&a=2&b[-1]=x&c[01]=y", URL.ParseOptions{
+		Dialect:           URL.DialectQS,
+		Duplicates:        URL.DuplicateDefault,
+		NegativeIndex:     URL.NegativeIndexReject,
+		NonCanonicalIndex: URL.NonCanonicalIndexReject,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := mapV.GetString("a"); s != "2" {
+		t.Errorf("expected 2 found %q", s)
+	}
+	if _, err := mapV.GetValue("b"); err == nil {
+		t.Error("b[-1]: expected the key to be ignored")
+	}
+	if _, err := mapV.GetValue("c"); err == nil {
+		t.Error("c[01]: expected the key to be ignored")
+	}
+
+	mapV, err = URL.ParseValuesWithOptions(url.Values{"a.b": {"x,y"}}, URL.ParseOptions{Dialect: URL.DialectQS})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := mapV.GetStrings("a", "b"); len(s) != 2 || s[1] != "y" {
+		t.Errorf("a[b]: expected [x y] found %v", s)
+	}
+}
@@ -0,0 +1,147 @@
+package url
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// IndexStyle defines how slice indices are encoded.
+type IndexStyle int
+
+const (
+	// IndexExplicit encodes slice elements with their index, "key[0]=a&key[1]=b"
+	IndexExplicit IndexStyle = iota
+	// IndexEmpty encodes slice elements with empty brackets, "key[]=a&key[]=b"
+	IndexEmpty
+	// IndexRepeat encodes slice elements repeating the key, "key=a&key=b"
+	IndexRepeat
+)
+
+// KeyOrder defines the order ValueMap keys are visited in.
+type KeyOrder int
+
+const (
+	// OrderSorted visits keys sorted by name
+	OrderSorted KeyOrder = iota
+	// OrderInsertion visits keys in the order they were added to the map,
+	// for parsed maps it is the order keys were processed by the parser.
+	OrderInsertion
+)
+
+// EncodeOptions configures Map.Encode().
+type EncodeOptions struct {
+	// IndexStyle defines how slice indices are encoded.
+	// Slice elements holding a ValueMap or a ValueSlice always use explicit indices,
+	// "key[][a]=1&key[][b]=2" would otherwise be parsed as two elements.
+	IndexStyle IndexStyle
+	// Order defines the order ValueMap keys are encoded in, slice elements are encoded by index.
+	Order KeyOrder
+	// SkipNil skips ValueNil elements, otherwise they are encoded as empty values.
+	SkipNil bool
+}
+
+// entry is an element of a ValueMap, ValueSlice or ValueArray.
+type entry struct {
+	// string for ValueMap elements, int for ValueSlice elements, either for ValueArray elements
+	key   any
+	value Value
+}
+
+// returns the elements of a ValueMap, ValueSlice or ValueArray, ValueMap and ValueArray keys follow order.
+// Sorted ValueArray keys list int keys first.
+func entries(val Value, order KeyOrder) (list []entry) {
+	switch val.Type() {
+	case ValueSlice:
+		s, _ := val.Slice()
+		list = make([]entry, len(s))
+		for i, v := range s {
+			list[i] = entry{key: i, value: v}
+		}
+	case ValueArray:
+		a := val.(*item).value.(*array)
+		keys := append([]any{}, a.keys...)
+		if order == OrderSorted {
+			sortArrayKeys(keys)
+		}
+		list = make([]entry, len(keys))
+		for i, k := range keys {
+			list[i] = entry{key: k, value: a.elements[k]}
+		}
+	case ValueMap:
+		m := val.(*item).value.(*orderedMap)
+		keys := m.keys
+		if order == OrderSorted {
+			keys = sortedKeys(m.elements)
+		}
+		list = make([]entry, len(keys))
+		for i, k := range keys {
+			list[i] = entry{key: k, value: m.elements[k]}
+		}
+	}
+	return list
+}
+
+// appends the key/value pairs found in val to pairs, key is the key of val.
+func encodePairs(pairs []field, key string, val Value, opts EncodeOptions) []field {
+	switch val.Type() {
+	case ValueNil:
+		if !opts.SkipNil && key != "" {
+			pairs = append(pairs, field{key: key, values: []string{""}})
+		}
+	case ValueString:
+		s, _ := val.String()
+		pairs = append(pairs, field{key: key, values: []string{s}})
+	case ValueSlice:
+		for _, e := range entries(val, opts.Order) {
+			elementKey := key + "[" + strconv.Itoa(e.key.(int)) + "]"
+			if e.value.Is(ValueString) || e.value.IsNil() {
+				switch opts.IndexStyle {
+				case IndexEmpty:
+					elementKey = key + "[]"
+				case IndexRepeat:
+					elementKey = key
+				}
+			}
+			pairs = encodePairs(pairs, elementKey, e.value, opts)
+		}
+	case ValueMap:
+		for _, e := range entries(val, opts.Order) {
+			pairs = encodePairs(pairs, childKey(key, e.key.(string)), e.value, opts)
+		}
+	case ValueArray:
+		for _, e := range entries(val, opts.Order) {
+			pairs = encodePairs(pairs, pathKey(key, e.key), e.value, opts)
+		}
+	}
+	return pairs
+}
+
+func (val *item) Values() url.Values {
+	out := make(url.Values)
+	for _, p := range encodePairs(nil, val.key, val, EncodeOptions{SkipNil: true}) {
+		out[p.key] = append(out[p.key], p.values...)
+	}
+	return out
+}
+
+// Encode encodes the Map as an application/x-www-form-urlencoded string.
+//
+// Unlike url.Values.Encode(), keys are not sorted: ValueMap keys are encoded following opts.Order
+// and slice elements by index, so that values appended with "key[]" keep their position.
+//
+// Using IndexExplicit and SkipNil the output parses back through ParseValues or ParseQuery into an equal tree,
+// with the exception of trailing ValueNil slice elements, ValueNil map values and empty maps and slices,
+// which cannot be represented.
+func (val *item) Encode(opts EncodeOptions) string {
+	var sb strings.Builder
+	for i, p := range encodePairs(nil, val.key, val, opts) {
+		if i > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(url.QueryEscape(p.key))
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(p.values[0]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package url_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+// reports whether a and b hold the same tree.
+func equalTree(a, b URL.Value) bool {
+	if a.Type() != b.Type() || a.Key() != b.Key() || a.Len() != b.Len() {
+		return false
+	}
+	switch a.Type() {
+	case URL.ValueString:
+		sa, _ := a.String()
+		sb, _ := b.String()
+		return sa == sb
+	case URL.ValueSlice:
+		sa, _ := a.Slice()
+		sb, _ := b.Slice()
+		for i := range sa {
+			if !equalTree(sa[i], sb[i]) {
+				return false
+			}
+		}
+	case URL.ValueMap:
+		ma, _ := a.Map()
+		mb, _ := b.Map()
+		for k, v := range ma {
+			if w, ok := mb[k]; !ok || !equalTree(v, w) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestEncode(t *testing.T) {
+	raw := "b=2&a[]=x&a[]=y&m[z]=1&m[k][0]=p&m[k][2]=q&rows[0][name]=n0&rows[1][name]=n1&e=&s=a+b%26c"
+	mapV, err := URL.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, opts := range []URL.EncodeOptions{
+			{SkipNil: true},
+			{SkipNil: true, Order: URL.OrderInsertion},
+		} {
+			encoded := mapV.Encode(opts)
+			parsed, err := URL.ParseQuery(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalTree(mapV.(URL.Value), parsed.(URL.Value)) {
+				t.Errorf("%s: expected an equal tree", encoded)
+			}
+		}
+		parsed, err := URL.ParseValues(mapV.Values())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalTree(mapV.(URL.Value), parsed.(URL.Value)) {
+			t.Errorf("Values(): expected an equal tree")
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		var tests = []struct {
+			opts     URL.EncodeOptions
+			expected string
+		}{
+			{URL.EncodeOptions{}, "a%5B0%5D=x&a%5B1%5D=y&b=2&e=&m%5Bk%5D%5B0%5D=p&m%5Bk%5D%5B1%5D=&m%5Bk%5D%5B2%5D=q&m%5Bz%5D=1&rows%5B0%5D%5Bname%5D=n0&rows%5B1%5D%5Bname%5D=n1&s=a+b%26c"},
+			{URL.EncodeOptions{SkipNil: true, Order: URL.OrderInsertion}, "b=2&a%5B0%5D=x&a%5B1%5D=y&m%5Bz%5D=1&m%5Bk%5D%5B0%5D=p&m%5Bk%5D%5B2%5D=q&rows%5B0%5D%5Bname%5D=n0&rows%5B1%5D%5Bname%5D=n1&e=&s=a+b%26c"},
+			{URL.EncodeOptions{SkipNil: true, IndexStyle: URL.IndexEmpty}, "a%5B%5D=x&a%5B%5D=y&b=2&e=&m%5Bk%5D%5B%5D=p&m%5Bk%5D%5B%5D=q&m%5Bz%5D=1&rows%5B0%5D%5Bname%5D=n0&rows%5B1%5D%5Bname%5D=n1&s=a+b%26c"},
+			{URL.EncodeOptions{SkipNil: true, IndexStyle: URL.IndexRepeat}, "a=x&a=y&b=2&e=&m%5Bk%5D=p&m%5Bk%5D=q&m%5Bz%5D=1&rows%5B0%5D%5Bname%5D=n0&rows%5B1%5D%5Bname%5D=n1&s=a+b%26c"},
+		}
+		for _, test := range tests {
+			if encoded := mapV.Encode(test.opts); encoded != test.expected {
+				t.Errorf("%+v: expected\n%s\nfound\n%s", test.opts, test.expected, encoded)
+			}
+		}
+	})
+
+	t.Run("values", func(t *testing.T) {
+		expected := url.Values{
+			"a[0]": {"x"}, "a[1]": {"y"}, "b": {"2"}, "e": {""}, "s": {"a b&c"},
+			"m[z]": {"1"}, "m[k][0]": {"p"}, "m[k][2]": {"q"},
+			"rows[0][name]": {"n0"}, "rows[1][name]": {"n1"},
+		}
+		if values := mapV.Values(); !reflect.DeepEqual(values, expected) {
+			t.Errorf("expected %v found %v", expected, values)
+		}
+		sub, _ := mapV.GetValue("m", "k")
+		if values := sub.Values(); !reflect.DeepEqual(values, url.Values{"m[k][0]": {"p"}, "m[k][2]": {"q"}}) {
+			t.Errorf("expected keys relative to the root, found %v", values)
+		}
+	})
+}
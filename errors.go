@@ -0,0 +1,129 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseErrorReason describes why a key could not be parsed.
+type ParseErrorReason int
+
+const (
+	// the key brackets are not balanced, ie "a]b[c"
+	ReasonMalformedKey ParseErrorReason = iota + 1
+	// the key addresses a slice index in a map or a map key in a slice
+	ReasonSliceMapConflict
+	// the key addresses a string as a map or slice, or a map or slice as a string
+	ReasonStringContainerConflict
+	// the key addresses a slice index the ParseOptions do not allow
+	ReasonInvalidIndex
+	// the key exceeds one of the ParseOptions limits
+	ReasonLimitExceeded
+	// the key/value pair cannot be decoded
+	ReasonInvalidEncoding
+	// the key was already submitted and ParseOptions.Duplicates is DuplicateError
+	ReasonDuplicateKey
+)
+
+func (r ParseErrorReason) String() string {
+	switch r {
+	case ReasonMalformedKey:
+		return "malformed key"
+	case ReasonSliceMapConflict:
+		return "slice/map conflict"
+	case ReasonStringContainerConflict:
+		return "string/container conflict"
+	case ReasonInvalidIndex:
+		return "invalid index"
+	case ReasonLimitExceeded:
+		return "limit exceeded"
+	case ReasonInvalidEncoding:
+		return "invalid encoding"
+	case ReasonDuplicateKey:
+		return "duplicate key"
+	}
+	return "unknown"
+}
+
+// ParseError reports a key ignored by the parser.
+type ParseError struct {
+	// Key is the raw key as submitted
+	Key string
+	// Segment is the part of the key where parsing failed, either the root name or a nested key
+	Segment string
+	// Reason classifies the error
+	Reason ParseErrorReason
+	// Err is the underlying error
+	Err error
+}
+
+// returns a *ParseError for the segment of key, val is the Value found at segment, if any.
+func newParseError(key, segment string, val Value, err error) *ParseError {
+	perr := &ParseError{Key: key, Segment: segment, Err: err}
+	switch {
+	case errors.Is(err, ErrLimitExceeded):
+		perr.Reason = ReasonLimitExceeded
+	case errors.Is(err, ErrInvalidIndex):
+		perr.Reason = ReasonInvalidIndex
+	case errors.Is(err, ErrMalformedKey):
+		perr.Reason = ReasonMalformedKey
+	case errors.Is(err, ErrDuplicateKey):
+		perr.Reason = ReasonDuplicateKey
+	case errors.Is(err, ErrValueNotString), val != nil && val.Is(ValueString):
+		perr.Reason = ReasonStringContainerConflict
+	default:
+		perr.Reason = ReasonSliceMapConflict
+	}
+	return perr
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%q at %q: %s: %v", e.Key, e.Segment, e.Reason, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors collects the errors reported by the parser in strict mode.
+//
+// errors.Is and errors.As inspect each of the collected errors.
+type ParseErrors struct {
+	Errors []*ParseError
+}
+
+func (e *ParseErrors) Error() string {
+	switch len(e.Errors) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e.Errors[0], len(e.Errors)-1)
+}
+
+func (e *ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// PathError reports an operation on a Map that failed at the element addressed by Path.
+type PathError struct {
+	// Op is the operation, ie "set"
+	Op string
+	// Path is the bracket path of the element where the operation failed
+	Path string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *PathError) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
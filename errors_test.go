@@ -0,0 +1,89 @@
+package url_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestParseStrict(t *testing.T) {
+	raw := "input[0]=a&input[key]=b&str=x&str[sub]=y&map[k]=v&map=w&bad]key[=z&neg[-1]=n&a[x]]y[=m&a]b[c]=q&ok=1"
+	mapV, err := URL.ParseQueryWithOptions(raw, URL.ParseOptions{Strict: true})
+	if mapV == nil {
+		t.Fatal("expected a Map")
+	}
+	if s := mapV.GetString("ok"); s != "1" {
+		t.Errorf("ok: expected 1 found %q", s)
+	}
+
+	var errs *URL.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected *ParseErrors, found %T", err)
+	}
+	expected := []struct {
+		key     string
+		segment string
+		reason  URL.ParseErrorReason
+	}{
+		{"input[key]", "key", URL.ReasonSliceMapConflict},
+		{"str[sub]", "sub", URL.ReasonStringContainerConflict},
+		{"map", "map", URL.ReasonStringContainerConflict},
+		{"bad]key[", "bad]key[", URL.ReasonMalformedKey},
+		{"neg[-1]", "-1", URL.ReasonInvalidIndex},
+		{"a[x]]y[", "a[x]]y[", URL.ReasonMalformedKey},
+		{"a]b[c]", "a]b[c]", URL.ReasonMalformedKey},
+	}
+	if len(errs.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, found %d: %v", len(expected), len(errs.Errors), err)
+	}
+	for i, e := range expected {
+		perr := errs.Errors[i]
+		if perr.Key != e.key || perr.Segment != e.segment || perr.Reason != e.reason {
+			t.Errorf("error %d: expected %q %q %s, found %q %q %s", i, e.key, e.segment, e.reason, perr.Key, perr.Segment, perr.Reason)
+		}
+	}
+	if !errors.Is(err, URL.ErrInvalidIndex) || !errors.Is(err, URL.ErrMalformedKey) {
+		t.Error("expected errors.Is to match the collected errors")
+	}
+
+	t.Run("not strict", func(t *testing.T) {
+		if _, err := URL.ParseQuery(raw); err != nil {
+			t.Errorf("expected ignored keys not to be reported, found %v", err)
+		}
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		if _, err := URL.ParseValuesWithOptions(url.Values{"a[]": {"1", "2"}}, URL.ParseOptions{Strict: true}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		_, err := URL.ParseQueryWithOptions("a=%zz&b=1", URL.ParseOptions{Strict: true})
+		var perr *URL.ParseError
+		if !errors.As(err, &perr) || perr.Reason != URL.ReasonInvalidEncoding {
+			t.Errorf("expected ReasonInvalidEncoding, found %v", err)
+		}
+		_, err = URL.ParseQueryWithOptions("a[%zz]=1", URL.ParseOptions{Strict: true})
+		if !errors.As(err, &perr) || perr.Key != "a[%zz]" || perr.Segment != "a[%zz]" {
+			t.Errorf("expected the raw key to be reported, found %v", err)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		mapV, err := URL.ParseQueryWithOptions("a[b]=1&c[d]=2&e[f][g]=3", URL.ParseOptions{Strict: true, MaxDepth: 1})
+		if mapV != nil {
+			t.Error("expected a nil Map")
+		}
+		var perr *URL.ParseError
+		if !errors.As(err, &perr) || perr.Reason != URL.ReasonLimitExceeded || perr.Segment != "g" {
+			t.Errorf("expected ReasonLimitExceeded at g, found %v", err)
+		}
+		var limitErr *URL.LimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != "MaxDepth" {
+			t.Errorf("expected MaxDepth *LimitError, found %v", err)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package url
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrStoreFull is returned by a FileStore that cannot hold more content.
+var ErrStoreFull = errors.New("file store full")
+
+// FileStore stores the content of the files read by ParseMultipartReader, see MultipartOptions.Store.
+//
+// The caller owns the store, Cleanup() should be called once the files are no longer needed,
+// also when parsing fails.
+type FileStore interface {
+	// Save stores the content of file read from r and returns the function opening the stored content.
+	// file holds the filename and the header of the part, its Size is set once Save returns.
+	Save(file *File, r io.Reader) (open func() (multipart.File, error), err error)
+	// Cleanup removes the stored files.
+	Cleanup() error
+}
+
+// MemoryStore is a FileStore keeping files in memory, the zero value is ready to use.
+type MemoryStore struct {
+	// MaxBytes is the maximum size of the files stored, zero or negative means no limit.
+	MaxBytes int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+func (s *MemoryStore) Save(file *File, r io.Reader) (func() (multipart.File, error), error) {
+	var buf bytes.Buffer
+	if s.MaxBytes > 0 {
+		s.mu.Lock()
+		remaining := s.MaxBytes - s.size
+		s.mu.Unlock()
+		r = io.LimitReader(r, remaining+1)
+	}
+	n, err := buf.ReadFrom(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exceeds64(s.size+n, s.MaxBytes) {
+		return nil, ErrStoreFull
+	}
+	s.size += n
+	content := buf.Bytes()
+	return func() (multipart.File, error) {
+		return memoryFile{io.NewSectionReader(bytes.NewReader(content), 0, int64(len(content)))}, nil
+	}, nil
+}
+
+// Cleanup releases the size accounted, the stored files remain readable until they are garbage collected.
+func (s *MemoryStore) Cleanup() error {
+	s.mu.Lock()
+	s.size = 0
+	s.mu.Unlock()
+	return nil
+}
+
+// memoryFile is a multipart.File reading from memory.
+type memoryFile struct {
+	*io.SectionReader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// TempDirStore is a FileStore writing files into a temporary directory, see NewTempDirStore.
+type TempDirStore struct {
+	dir string
+}
+
+// NewTempDirStore creates a new directory in dir and returns the store writing into it.
+// If dir is the empty string os.TempDir() is used. Cleanup() removes the directory and its content.
+func NewTempDirStore(dir string) (*TempDirStore, error) {
+	path, err := os.MkdirTemp(dir, "url-upload-")
+	if err != nil {
+		return nil, err
+	}
+	return &TempDirStore{dir: path}, nil
+}
+
+// Dir returns the directory files are written into.
+func (s *TempDirStore) Dir() string {
+	return s.dir
+}
+
+func (s *TempDirStore) Save(file *File, r io.Reader) (func() (multipart.File, error), error) {
+	f, err := os.CreateTemp(s.dir, "file-")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	path := f.Name()
+	return func() (multipart.File, error) {
+		return os.Open(path)
+	}, nil
+}
+
+func (s *TempDirStore) Cleanup() error {
+	return os.RemoveAll(s.dir)
+}
+
+// FileHook observes the content of an uploaded file, see MultipartOptions.Hooks.
+//
+// FileHook is called before the content of file is read, w receives the content as it is streamed
+// and done is called once the whole content has been read, done can be nil.
+type FileHook func(file *File) (w io.Writer, done func())
+
+// SniffContentType returns a FileHook setting File.ContentType with http.DetectContentType,
+// the header sent by the client is not trusted.
+func SniffContentType() FileHook {
+	return func(file *File) (io.Writer, func()) {
+		sniff := &headWriter{max: 512}
+		return sniff, func() {
+			file.ContentType = http.DetectContentType(sniff.buf)
+		}
+	}
+}
+
+// SHA256Checksum returns a FileHook setting File.SHA256 to the checksum of the content.
+func SHA256Checksum() FileHook {
+	return func(file *File) (io.Writer, func()) {
+		var h hash.Hash = sha256.New()
+		return h, func() {
+			file.SHA256 = h.Sum(nil)
+		}
+	}
+}
+
+// headWriter keeps the first max bytes written.
+type headWriter struct {
+	buf []byte
+	max int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if n := w.max - len(w.buf); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf = append(w.buf, p[:n]...)
+	}
+	return len(p), nil
+}
+
+// streams r into store applying hooks, sets the size and the content of file.
+func storeFile(file *File, r io.Reader, store FileStore, hooks []FileHook) error {
+	writers := make([]io.Writer, 0, len(hooks))
+	var done []func()
+	for _, hook := range hooks {
+		w, d := hook(file)
+		if w != nil {
+			writers = append(writers, w)
+		}
+		if d != nil {
+			done = append(done, d)
+		}
+	}
+	counter := &countingReader{r: io.TeeReader(r, io.MultiWriter(writers...))}
+	open, err := store.Save(file, counter)
+	if err != nil {
+		return err
+	}
+	file.Size, file.open = counter.n, open
+	for _, d := range done {
+		d()
+	}
+	return nil
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,118 @@
+package url_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestFileStore(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n" + "image-data"
+	parts := [][3]string{
+		{"title", "", "Photos"},
+		{"photos[]", "a.png", png},
+		{"photos[]", "b.txt", "plain text"},
+	}
+
+	t.Run("temp dir", func(t *testing.T) {
+		store, err := URL.NewTempDirStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, boundary := testMultipart(t, parts...)
+		mapV, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{
+			Store: store,
+			Hooks: []URL.FileHook{URL.SniffContentType(), URL.SHA256Checksum()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := os.ReadDir(store.Dir())
+		if len(entries) != 2 {
+			t.Errorf("expected 2 files in %s found %d", store.Dir(), len(entries))
+		}
+
+		v, _ := mapV.GetValue("photos", 0)
+		f, _ := v.File()
+		if s := testFileContent(t, v); s != png || f.Size != int64(len(png)) {
+			t.Errorf("unexpected content %q (%d bytes)", s, f.Size)
+		}
+		if f.ContentType != "image/png" {
+			t.Errorf("expected image/png found %q", f.ContentType)
+		}
+		if sum := sha256.Sum256([]byte(png)); !bytes.Equal(f.SHA256, sum[:]) {
+			t.Errorf("unexpected checksum %x", f.SHA256)
+		}
+		v, _ = mapV.GetValue("photos", 1)
+		if f, _ := v.File(); f.ContentType != "text/plain; charset=utf-8" {
+			t.Errorf("expected text/plain found %q", f.ContentType)
+		}
+
+		if err := store.Cleanup(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(store.Dir()); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, found %v", store.Dir(), err)
+		}
+		if _, err := f.Open(); err == nil {
+			t.Error("expected the file to be removed")
+		}
+	})
+
+	t.Run("memory cap", func(t *testing.T) {
+		buf, boundary := testMultipart(t, parts...)
+		_, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{Store: &URL.MemoryStore{MaxBytes: 20}})
+		var limitErr *URL.LimitError
+		if !errors.Is(err, URL.ErrStoreFull) || !errors.As(err, &limitErr) || limitErr.Limit != "Store" {
+			t.Errorf("expected ErrStoreFull found %v", err)
+		}
+		store := &URL.MemoryStore{MaxBytes: 28}
+		buf, boundary = testMultipart(t, parts...)
+		if _, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{Store: store}); err != nil {
+			t.Errorf("expected no error found %v", err)
+		}
+		store.Cleanup()
+		buf, boundary = testMultipart(t, parts...)
+		if _, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{Store: store}); err != nil {
+			t.Errorf("expected no error after Cleanup found %v", err)
+		}
+	})
+
+	t.Run("form hooks", func(t *testing.T) {
+		buf, boundary := testMultipart(t, parts...)
+		form, err := multipart.NewReader(buf, boundary).ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer form.RemoveAll()
+		mapV, err := URL.ParseMultipartWithOptions(form, URL.MultipartOptions{Hooks: []URL.FileHook{URL.SHA256Checksum()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := mapV.GetValue("photos", 0)
+		f, _ := v.File()
+		if sum := sha256.Sum256([]byte(png)); !bytes.Equal(f.SHA256, sum[:]) {
+			t.Errorf("unexpected checksum %x", f.SHA256)
+		}
+		if s := testFileContent(t, v); s != png {
+			t.Errorf("unexpected content %q", s)
+		}
+	})
+
+	t.Run("temp dir default", func(t *testing.T) {
+		store, err := URL.NewTempDirStore("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Cleanup()
+		if filepath.Dir(store.Dir()) != filepath.Clean(os.TempDir()) {
+			t.Errorf("expected a directory in %s found %s", os.TempDir(), store.Dir())
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package url
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// returns the string at keys, op names the getter in the returned *PathError.
+func (val *item) lookupString(op string, keys []any) (s string, path string, err error) {
+	out, err := val.walkPath(op, keys, false)
+	if err != nil {
+		return "", "", err
+	}
+	if !out.Is(ValueString) {
+		return "", out.key, &PathError{Op: op, Path: out.key, Err: ErrValueNotString}
+	}
+	s, _ = out.String()
+	return s, out.key, nil
+}
+
+func (val *item) GetInt(keys ...any) (int, error) {
+	s, path, err := val.lookupString("GetInt", keys)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &PathError{Op: "GetInt", Path: path, Err: err}
+	}
+	return i, nil
+}
+
+func (val *item) GetIntOr(def int, keys ...any) int {
+	if i, err := val.GetInt(keys...); err == nil {
+		return i
+	}
+	return def
+}
+
+func (val *item) GetInt64(keys ...any) (int64, error) {
+	s, path, err := val.lookupString("GetInt64", keys)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, &PathError{Op: "GetInt64", Path: path, Err: err}
+	}
+	return i, nil
+}
+
+func (val *item) GetInt64Or(def int64, keys ...any) int64 {
+	if i, err := val.GetInt64(keys...); err == nil {
+		return i
+	}
+	return def
+}
+
+func (val *item) GetUint(keys ...any) (uint, error) {
+	s, path, err := val.lookupString("GetUint", keys)
+	if err != nil {
+		return 0, err
+	}
+	u, err := strconv.ParseUint(s, 10, strconv.IntSize)
+	if err != nil {
+		return 0, &PathError{Op: "GetUint", Path: path, Err: err}
+	}
+	return uint(u), nil
+}
+
+func (val *item) GetUintOr(def uint, keys ...any) uint {
+	if u, err := val.GetUint(keys...); err == nil {
+		return u
+	}
+	return def
+}
+
+func (val *item) GetFloat(keys ...any) (float64, error) {
+	s, path, err := val.lookupString("GetFloat", keys)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, &PathError{Op: "GetFloat", Path: path, Err: err}
+	}
+	return f, nil
+}
+
+func (val *item) GetFloatOr(def float64, keys ...any) float64 {
+	if f, err := val.GetFloat(keys...); err == nil {
+		return f
+	}
+	return def
+}
+
+func (val *item) GetBool(keys ...any) (bool, error) {
+	s, path, err := val.lookupString("GetBool", keys)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseBool(s)
+	if err != nil {
+		return false, &PathError{Op: "GetBool", Path: path, Err: err}
+	}
+	return b, nil
+}
+
+func (val *item) GetBoolOr(def bool, keys ...any) bool {
+	if b, err := val.GetBool(keys...); err == nil {
+		return b
+	}
+	return def
+}
+
+func (val *item) GetTime(layout string, keys ...any) (time.Time, error) {
+	s, path, err := val.lookupString("GetTime", keys)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, &PathError{Op: "GetTime", Path: path, Err: err}
+	}
+	return t, nil
+}
+
+func (val *item) GetTimeOr(layout string, def time.Time, keys ...any) time.Time {
+	if t, err := val.GetTime(layout, keys...); err == nil {
+		return t
+	}
+	return def
+}
+
+func (val *item) GetDuration(keys ...any) (time.Duration, error) {
+	s, path, err := val.lookupString("GetDuration", keys)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, &PathError{Op: "GetDuration", Path: path, Err: err}
+	}
+	return d, nil
+}
+
+func (val *item) GetDurationOr(def time.Duration, keys ...any) time.Duration {
+	if d, err := val.GetDuration(keys...); err == nil {
+		return d
+	}
+	return def
+}
+
+// returns the Value at path, op names the function in the returned *PathError.
+// Map implementations of other packages are walked with their own GetValue().
+func lookup(m Map, op string, path []any) (Value, error) {
+	if m == nil {
+		return nil, &PathError{Op: op, Path: Path(path).String(), Err: ErrNilMap}
+	}
+	root, err := m.GetValue()
+	if err != nil {
+		return nil, err
+	}
+	if it, ok := root.(*item); ok {
+		return it.walkPath(op, path, false)
+	}
+	return m.GetValue(path...)
+}
+
+// Get returns the ValueString at path converted to T, see Convert().
+//
+//	id, err := url.Get[uuid.UUID](mapV, "user", "id")
+//
+// If m is nil, or the lookup or the conversion fails, it returns a *PathError holding the bracket path of the value.
+func Get[T any](m Map, path ...any) (out T, err error) {
+	v, err := lookup(m, "Get", path)
+	if err != nil {
+		return out, err
+	}
+	if !v.Is(ValueString) {
+		return out, &PathError{Op: "Get", Path: v.Key(), Err: ErrValueNotString}
+	}
+	s, _ := v.String()
+	if err = convertString(s, reflect.ValueOf(&out).Elem()); err != nil {
+		return out, &PathError{Op: "Get", Path: v.Key(), Err: err}
+	}
+	return out, nil
+}
+
+// GetSlice returns the elements of the ValueSlice or ValueArray at path converted to T, see Convert().
+//
+// ValueNil elements result in the zero value of T, a ValueString results in a single element.
+// If m is nil, or the lookup or the conversion fails, it returns a *PathError holding the bracket path of the value.
+func GetSlice[T any](m Map, path ...any) ([]T, error) {
+	v, err := lookup(m, "GetSlice", path)
+	if err != nil {
+		return nil, err
+	}
+	elements := []Value{v}
+	if v.Is(ValueSlice) || v.Is(ValueArray) {
+		elements = v.ToSlice()
+	}
+	out := make([]T, len(elements))
+	for i, e := range elements {
+		if e.IsNil() {
+			continue
+		}
+		if !e.Is(ValueString) {
+			return nil, &PathError{Op: "GetSlice", Path: e.Key(), Err: ErrValueNotString}
+		}
+		s, _ := e.String()
+		if err := convertString(s, reflect.ValueOf(&out[i]).Elem()); err != nil {
+			return nil, &PathError{Op: "GetSlice", Path: e.Key(), Err: err}
+		}
+	}
+	return out, nil
+}
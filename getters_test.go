@@ -0,0 +1,108 @@
+package url_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestTypedGetters(t *testing.T) {
+	mapV, err := URL.ParseQuery("user[age]=42&user[big]=-9000000000&user[score]=9.5&user[agree]=on&user[off]=0&user[born]=2024-01-02&user[ttl]=1h30m&user[name]=John&list[]=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i, err := mapV.GetInt("user", "age"); err != nil || i != 42 {
+		t.Errorf("GetInt: expected 42 found %d (%v)", i, err)
+	}
+	if i, err := mapV.GetInt64("user", "big"); err != nil || i != -9000000000 {
+		t.Errorf("GetInt64: expected -9000000000 found %d (%v)", i, err)
+	}
+	if u, err := mapV.GetUint("user", "age"); err != nil || u != 42 {
+		t.Errorf("GetUint: expected 42 found %d (%v)", u, err)
+	}
+	if f, err := mapV.GetFloat("user", "score"); err != nil || f != 9.5 {
+		t.Errorf("GetFloat: expected 9.5 found %f (%v)", f, err)
+	}
+	if b, err := mapV.GetBool("user", "agree"); err != nil || !b {
+		t.Errorf("GetBool: expected true found %v (%v)", b, err)
+	}
+	if b, err := mapV.GetBool("user", "off"); err != nil || b {
+		t.Errorf("GetBool: expected false found %v (%v)", b, err)
+	}
+	if tm, err := mapV.GetTime("2006-01-02", "user", "born"); err != nil || !tm.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime: unexpected %v (%v)", tm, err)
+	}
+	if d, err := mapV.GetDuration("user", "ttl"); err != nil || d != 90*time.Minute {
+		t.Errorf("GetDuration: expected 1h30m found %v (%v)", d, err)
+	}
+	if i, err := mapV.GetInt("list", 0); err != nil || i != 1 {
+		t.Errorf("GetInt: expected 1 found %d (%v)", i, err)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		if i := mapV.GetIntOr(7, "user", "missing"); i != 7 {
+			t.Errorf("GetIntOr: expected 7 found %d", i)
+		}
+		if i := mapV.GetInt64Or(7, "user", "name"); i != 7 {
+			t.Errorf("GetInt64Or: expected 7 found %d", i)
+		}
+		if u := mapV.GetUintOr(7, "user", "big"); u != 7 {
+			t.Errorf("GetUintOr: expected 7 found %d", u)
+		}
+		if f := mapV.GetFloatOr(1.5, "user"); f != 1.5 {
+			t.Errorf("GetFloatOr: expected 1.5 found %f", f)
+		}
+		if b := mapV.GetBoolOr(true, "user", "name"); !b {
+			t.Error("GetBoolOr: expected true")
+		}
+		if b := mapV.GetBoolOr(true, "user", "agree"); !b {
+			t.Error("GetBoolOr: expected true")
+		}
+		def := time.Unix(0, 0)
+		if tm := mapV.GetTimeOr(time.RFC3339, def, "user", "born"); !tm.Equal(def) {
+			t.Errorf("GetTimeOr: expected default found %v", tm)
+		}
+		if d := mapV.GetDurationOr(time.Second, "nope"); d != time.Second {
+			t.Errorf("GetDurationOr: expected 1s found %v", d)
+		}
+		if i := mapV.GetIntOr(7, "user", "age"); i != 42 {
+			t.Errorf("GetIntOr: expected 42 found %d", i)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var tests = []struct {
+			err  error
+			path string
+			is   error
+		}{
+			{second(mapV.GetInt("user", "name")), "user[name]", strconv.ErrSyntax},
+			{second(mapV.GetInt("user", "missing")), "user[missing]", URL.ErrKeyNotFound},
+			{second(mapV.GetInt("user", "age", "sub")), "user[age][sub]", URL.ErrValueNotMap},
+			{second(mapV.GetInt("user")), "user", URL.ErrValueNotString},
+			{second(mapV.GetBool("user", "name")), "user[name]", nil},
+			{second(mapV.GetInt("list", 3)), "list[3]", URL.ErrKeyNotFound},
+		}
+		for _, test := range tests {
+			var perr *URL.PathError
+			if !errors.As(test.err, &perr) {
+				t.Errorf("expected *PathError found %v", test.err)
+				continue
+			}
+			if perr.Path != test.path {
+				t.Errorf("expected path %s found %s", test.path, perr.Path)
+			}
+			if test.is != nil && !errors.Is(test.err, test.is) {
+				t.Errorf("%v: expected %v", test.err, test.is)
+			}
+		}
+	})
+}
+
+func second[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,47 @@
+package url
+
+import "net/http"
+
+// Handler returns an http.Handler binding the parameters of each request into a T and calling fn with it.
+//
+//	type Search struct {
+//		Query string `url:"q,required"`
+//		Page  int    `url:"page"`
+//	}
+//
+//	mux.Handle("/search", url.Handler(func(w http.ResponseWriter, r *http.Request, in Search) {
+//		// in.Query is set
+//	}))
+//
+// The request is parsed with ParseRequest and its default limits, or read with FromContext when Handler is wrapped by Middleware,
+// the Map is stored into a T and checked with Bind.
+// Requests that cannot be parsed, bound or validated are answered by ErrorProblem, invalid parameters with
+// status 422 listing the bracket path of each of them.
+func Handler[T any](fn func(w http.ResponseWriter, r *http.Request, in T)) http.Handler {
+	return HandlerWithOptions(MiddlewareOptions{}, fn)
+}
+
+// HandlerWithOptions behaves as Handler, parsing requests with opts.RequestOptions and replying to errors with opts.ErrorHandler.
+// Uploaded files are stored as Middleware does and removed once fn returns, HandlerWithOptions panics if opts.Store is set.
+func HandlerWithOptions[T any](opts MiddlewareOptions, fn func(w http.ResponseWriter, r *http.Request, in T)) http.Handler {
+	onError := opts.errorHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := FromContext(r.Context())
+		if m == nil {
+			var store FileStore
+			var err error
+			m, store, err = parseRequest(r, opts)
+			defer store.Cleanup()
+			if err != nil {
+				onError(w, r, err)
+				return
+			}
+		}
+		var in T
+		if err := Bind(m, &in); err != nil {
+			onError(w, r, err)
+			return
+		}
+		fn(w, r, in)
+	})
+}
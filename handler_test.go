@@ -0,0 +1,115 @@
+package url_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+type testSearch struct {
+	Query string `url:"q,required"`
+	Page  int    `url:"page"`
+}
+
+func TestHandler(t *testing.T) {
+	var got testSearch
+	var calls int
+	h := URL.Handler(func(w http.ResponseWriter, r *http.Request, in testSearch) {
+		calls++
+		got = in
+	})
+
+	t.Run("bind", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, testFormRequest("/?q=go&page=1", "page=2"))
+		if calls != 1 || rec.Code != http.StatusOK {
+			t.Fatalf("expected handler to be called, found %d calls and status %d", calls, rec.Code)
+		}
+		if got.Query != "go" || got.Page != 2 {
+			t.Errorf("unexpected input %+v", got)
+		}
+	})
+	t.Run("validation", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		URL.Handler(func(w http.ResponseWriter, r *http.Request, in *testSignup) {
+			calls++
+		}).ServeHTTP(rec, testFormRequest("/", "address[zip]=1"))
+		if calls != 0 || rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, found %d calls and status %d", calls, rec.Code)
+		}
+		p := testProblem(t, rec)
+		if len(p.Errors) != 2 || p.Errors[0].Path != "email" || p.Errors[1].Path != "address[city]" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, testFormRequest("/?q=go&page=x", ""))
+		if calls != 0 || rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, found %d calls and status %d", calls, rec.Code)
+		}
+		if p := testProblem(t, rec); len(p.Errors) != 1 || p.Errors[0].Path != "page" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("every path", func(t *testing.T) {
+		type input struct {
+			A int    `url:"a"`
+			B int    `url:"b"`
+			C string `url:"c,required"`
+		}
+		rec := httptest.NewRecorder()
+		URL.Handler(func(w http.ResponseWriter, r *http.Request, in input) {}).ServeHTTP(rec, testFormRequest("/?a=x&b=y", ""))
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, found %d", rec.Code)
+		}
+		p := testProblem(t, rec)
+		var paths []string
+		for _, e := range p.Errors {
+			paths = append(paths, e.Path)
+		}
+		if len(paths) != 3 || paths[0] != "a" || paths[1] != "b" || paths[2] != "c" {
+			t.Errorf("expected [a b c], found %v", paths)
+		}
+	})
+	t.Run("default limits", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q=go&a[2000000]=x", nil))
+		if calls != 0 || rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status 413, found %d calls and status %d", calls, rec.Code)
+		}
+	})
+	t.Run("bad request", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, testFormRequest("/?q=%zz", ""))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, found %d", rec.Code)
+		}
+	})
+	t.Run("middleware", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		mw := URL.Middleware(URL.MiddlewareOptions{RequestOptions: URL.RequestOptions{Order: "P"}})
+		mw(h).ServeHTTP(rec, testFormRequest("/?page=3", "q=body"))
+		if calls != 1 || got.Query != "body" || got.Page != 0 {
+			t.Errorf("expected the Map parsed by Middleware, found %+v", got)
+		}
+	})
+	t.Run("options", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		opts := URL.MiddlewareOptions{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(http.StatusTeapot)
+		}}
+		URL.HandlerWithOptions(opts, func(w http.ResponseWriter, r *http.Request, in testSearch) {}).
+			ServeHTTP(rec, testFormRequest("/", ""))
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("expected status 418, found %d", rec.Code)
+		}
+	})
+}
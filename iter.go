@@ -0,0 +1,52 @@
+package url
+
+import "iter"
+
+func (val *item) All() iter.Seq2[Path, Value] {
+	return func(yield func(Path, Value) bool) {
+		val.Walk(func(path Path, v Value) error {
+			if !yield(path, v) {
+				return StopWalk
+			}
+			return nil
+		})
+	}
+}
+
+func (val *item) Leaves() iter.Seq2[Path, Value] {
+	return func(yield func(Path, Value) bool) {
+		val.Walk(func(path Path, v Value) error {
+			if v.Is(ValueMap) || v.Is(ValueSlice) || v.Is(ValueArray) {
+				return nil
+			}
+			if !yield(path, v) {
+				return StopWalk
+			}
+			return nil
+		})
+	}
+}
+
+func (val *item) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if !val.Is(ValueMap) {
+			return
+		}
+		for _, k := range val.StringKeys() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (val *item) Elements() iter.Seq2[int, Value] {
+	return func(yield func(int, Value) bool) {
+		s, _ := val.Slice()
+		for i, v := range s {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package url_test
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestIterators(t *testing.T) {
+	mapV, err := URL.ParseQuery("b[]=1&b[]=2&a=x&c[k]=v&c[j]=w")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all []string
+	for path := range mapV.All() {
+		all = append(all, path.String())
+	}
+	if expect := []string{"a", "b", "b[0]", "b[1]", "c", "c[j]", "c[k]"}; !reflect.DeepEqual(all, expect) {
+		t.Errorf("All: expected %v found %v", expect, all)
+	}
+
+	leaves := map[string]string{}
+	for path, v := range mapV.Leaves() {
+		leaves[path.DotString()], _ = v.String()
+	}
+	if expect := map[string]string{"a": "x", "b.0": "1", "b.1": "2", "c.j": "w", "c.k": "v"}; !maps.Equal(leaves, expect) {
+		t.Errorf("Leaves: expected %v found %v", expect, leaves)
+	}
+
+	if keys := slices.Collect(mapV.Keys()); !slices.Equal(keys, []string{"b", "a", "c"}) {
+		t.Errorf("Keys: unexpected %v", keys)
+	}
+
+	b, _ := mapV.GetValue("b")
+	var elements []string
+	for i, v := range b.Elements() {
+		s, _ := v.String()
+		elements = append(elements, s)
+		if v.Key() != (URL.Path{"b", i}).String() {
+			t.Errorf("Elements: unexpected key %s at %d", v.Key(), i)
+		}
+	}
+	if !slices.Equal(elements, []string{"1", "2"}) {
+		t.Errorf("Elements: unexpected %v", elements)
+	}
+	if keys := slices.Collect(b.Keys()); len(keys) != 0 {
+		t.Errorf("Keys: expected no keys for a slice, found %v", keys)
+	}
+
+	t.Run("break", func(t *testing.T) {
+		var n int
+		for range mapV.All() {
+			n++
+			if n == 2 {
+				break
+			}
+		}
+		if n != 2 {
+			t.Errorf("expected 2 iterations found %d", n)
+		}
+		for path := range mapV.Leaves() {
+			if path.String() != "a" {
+				t.Errorf("expected a found %s", path)
+			}
+			break
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package url
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONScalarPolicy defines how FromJSON handles JSON numbers and booleans.
+type JSONScalarPolicy int
+
+const (
+	// JSONScalarText stores numbers as written in the document and booleans as "true" or "false"
+	JSONScalarText JSONScalarPolicy = iota
+	// JSONScalarNil stores numbers and booleans as ValueNil
+	JSONScalarNil
+	// JSONScalarError fails when a number or a boolean is found
+	JSONScalarError
+)
+
+// JSONOptions configures FromJSONWithOptions.
+type JSONOptions struct {
+	// Scalars defines how numbers and booleans are stored, strings are stored as ValueString and null as ValueNil.
+	Scalars JSONScalarPolicy
+}
+
+// MarshalJSON encodes ValueMap as an object, ValueSlice as an array, ValueString as a string and ValueNil as null.
+// ValueArray is encoded as an array if its keys are 0 to Len()-1 in order, as an object otherwise.
+// ValueFile is encoded as an object holding the filename and the size of the file.
+// ValueMap and ValueArray keys are encoded in insertion order.
+func (val *item) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writes the JSON encoding of val to buf
+func encodeJSON(buf *bytes.Buffer, val Value) error {
+	switch val.Type() {
+	case ValueString:
+		s, _ := val.String()
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case ValueFile:
+		f, _ := val.File()
+		b, err := json.Marshal(struct {
+			Filename string `json:"filename"`
+			Size     int64  `json:"size"`
+		}{f.Filename, f.Size})
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case ValueSlice, ValueMap, ValueArray:
+		open, close := byte('['), byte(']')
+		object := val.Is(ValueMap) || val.Is(ValueArray) && !isList(val)
+		if object {
+			open, close = '{', '}'
+		}
+		buf.WriteByte(open)
+		for i, e := range entries(val, OrderInsertion) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if object {
+				b, err := json.Marshal(fmt.Sprint(e.key))
+				if err != nil {
+					return err
+				}
+				buf.Write(b)
+				buf.WriteByte(':')
+			}
+			if err := encodeJSON(buf, e.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(close)
+	default:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+// FromJSON builds a Map from a JSON document, see FromJSONWithOptions.
+func FromJSON(data []byte) (Map, error) {
+	return FromJSONWithOptions(data, JSONOptions{})
+}
+
+// FromJSONWithOptions builds a Map from a JSON document, the document must be an object.
+//
+// Objects become ValueMap, arrays ValueSlice, strings ValueString and null ValueNil,
+// numbers and booleans are handled according to opts.Scalars. Keys are stored in the order
+// they appear in the document, when a key is repeated the last value is kept.
+func FromJSONWithOptions(data []byte, opts JSONOptions) (Map, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("JSON document is not an object")
+	}
+	out := newNilValue("")
+	if err := decodeJSON(dec, out, tok, opts); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON document")
+	}
+	return out, nil
+}
+
+// stores into val the JSON value starting with tok.
+func decodeJSON(dec *json.Decoder, val *item, tok json.Token, opts JSONOptions) (err error) {
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			val.to(ValueMap)
+		} else {
+			val.to(ValueSlice)
+		}
+		for dec.More() {
+			var nested Value
+			if val.Is(ValueMap) {
+				var k json.Token
+				if k, err = dec.Token(); err != nil {
+					return err
+				}
+				if nested, err = val.mapFor(k.(string)); err != nil {
+					return err
+				}
+			} else if nested, err = val.newNilValueAt(-1); err != nil {
+				return err
+			}
+			if tok, err = dec.Token(); err != nil {
+				return err
+			}
+			element := nested.(*item)
+			// repeated keys replace the previous value
+			element.valueType, element.value = ValueNil, ""
+			if err = decodeJSON(dec, element, tok, opts); err != nil {
+				return err
+			}
+		}
+		// closing delimiter
+		_, err = dec.Token()
+		return err
+	case string:
+		val.to(ValueString).setValue(t)
+	case json.Number, bool:
+		switch opts.Scalars {
+		case JSONScalarNil:
+		case JSONScalarError:
+			return fmt.Errorf("%s: unexpected JSON value %v", val.key, t)
+		default:
+			val.to(ValueString).setValue(fmt.Sprint(t))
+		}
+	}
+	return nil
+}
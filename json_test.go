@@ -0,0 +1,89 @@
+package url_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestJSON(t *testing.T) {
+	mapV, err := URL.ParseQuery("z=1&a[]=x&a[]=y&m[k][2]=q&m[b]=%3Ctag%3E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"z":"1","a":["x","y"],"m":{"k":[null,null,"q"],"b":"\u003ctag\u003e"}}`
+	b, err := json.Marshal(mapV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expected {
+		t.Errorf("expected %s found %s", expected, b)
+	}
+
+	t.Run("value", func(t *testing.T) {
+		v, _ := mapV.GetValue("a")
+		b, err := json.Marshal(struct{ A URL.Value }{v})
+		if err != nil || string(b) != `{"A":["x","y"]}` {
+			t.Errorf("unexpected %s (%v)", b, err)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		fromJSON, err := URL.FromJSON([]byte(expected))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalTree(mapV.(URL.Value), fromJSON.(URL.Value)) {
+			t.Error("expected an equal tree")
+		}
+		if b, _ := json.Marshal(fromJSON); string(b) != expected {
+			t.Errorf("expected %s found %s", expected, b)
+		}
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		doc := []byte(`{"n":1.50,"b":true,"s":"x","a":[1,false]}`)
+		fromJSON, err := URL.FromJSON(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fromJSON.GetString("n"); s != "1.50" {
+			t.Errorf("n: expected 1.50 found %q", s)
+		}
+		if s := fromJSON.GetString("a", 1); s != "false" {
+			t.Errorf("a[1]: expected false found %q", s)
+		}
+		fromJSON, err = URL.FromJSONWithOptions(doc, URL.JSONOptions{Scalars: URL.JSONScalarNil})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := fromJSON.GetValue("b"); !v.IsNil() {
+			t.Errorf("b: expected ValueNil found %s", v.Type())
+		}
+		if s := fromJSON.GetString("s"); s != "x" {
+			t.Errorf("s: expected x found %q", s)
+		}
+		if _, err := URL.FromJSONWithOptions(doc, URL.JSONOptions{Scalars: URL.JSONScalarError}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, doc := range []string{`[]`, `"a"`, `{"a":}`, `{"a":"b"} {}`, `{"a":["b"}`} {
+			if _, err := URL.FromJSON([]byte(doc)); err == nil {
+				t.Errorf("%s: expected an error", doc)
+			}
+		}
+	})
+
+	t.Run("repeated keys", func(t *testing.T) {
+		fromJSON, err := URL.FromJSON([]byte(`{"a":{"b":"c"},"a":"d"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fromJSON.GetString("a"); s != "d" {
+			t.Errorf("a: expected d found %q", s)
+		}
+	})
+}
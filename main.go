@@ -48,6 +48,9 @@
 //
 //	sort.Strings(keys)
 //
+// ParseQuery() and ParseReader() are not affected by the issue, they tokenize the raw
+// application/x-www-form-urlencoded payload themselves and process keys in submission order.
+//
 // Below some pivotal points I believe you should consider when creating Form/QueryString.
 // In the examples below {} indicates an hash map, [] indicates an array.
 //
@@ -106,21 +109,40 @@
 //	 // or
 //		"{ input : { key: b } }"
 //
-// 5. Malformed Key/Value pairs are ignored
+// set ParseOptions.MixedKeys to keep every value in a ValueArray, an ordered hash holding both int and string keys
+//
+//	"{ input : [ 0 => a, key => b, 2 => c ] }"
+//
+// 5. Malformed Key/Value pairs are ignored, set ParseOptions.Strict to have each of them reported in a *ParseErrors
+//
+// 6. Negative indices ("input[-1]") and indices not in canonical form ("input[01]", "input[+1]") are ignored by default,
+// ParseOptions.NegativeIndex and ParseOptions.NonCanonicalIndex allow to handle them as map keys, to count
+// negative indices from the end of the slice or to normalize "input[01]" as "input[1]".
+//
+// 7. Only one value is stored for a key submitted more than once, ParseOptions.Duplicates allows to keep the first
+// or the last value, to report duplicates as errors or to promote the key to a ValueSlice holding every value.
 package url
 
 import (
 	"errors"
-	"fmt"
+	"iter"
+	"maps"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var ErrValueNotSlice = errors.New("Value is not a slice")
 var ErrValueNotMap = errors.New("Value is not a map")
 var ErrValueNotMapOrSlice = errors.New("Value is not a map or slice")
+var ErrValueNotString = errors.New("Value is not a string")
+var ErrValueNotArray = errors.New("Value is not an array")
+var ErrMalformedKey = errors.New("malformed key")
+var ErrKeyNotFound = errors.New("key not found")
+var ErrEmptyPath = errors.New("empty path")
+var ErrNilMap = errors.New("nil Map")
 
 // sorts url.Values by key
 func sortUrlValues(src url.Values) (keys []string) {
@@ -134,6 +156,29 @@ func sortUrlValues(src url.Values) (keys []string) {
 	return keys
 }
 
+// returns the keys of m sorted by name
+func sortedKeys(m map[string]Value) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// returns the key of the element k of parent, root elements have no brackets.
+func childKey(parent, k string) string {
+	if parent == "" {
+		return k
+	}
+	return parent + "[" + k + "]"
+}
+
+// returns the key of the element at index of parent.
+func elementKey(parent string, index int) string {
+	return parent + "[" + strconv.Itoa(index) + "]"
+}
+
 // splits key name from possible brackets
 func getParseKey(key string) (root string, nestedKeys []string, err error) {
 	root = key
@@ -141,15 +186,23 @@ func getParseKey(key string) (root string, nestedKeys []string, err error) {
 	if strings.Contains(root, "[") && strings.Contains(root, "]") {
 		root = key[:strings.Index(root, "[")]
 		strKeys = key[len(root):]
+		if strings.Contains(root, "]") {
+			// a bracket is closed before the first one is opened, "a]b[c]" is malformed
+			err = ErrMalformedKey
+			return
+		}
 	}
 	nestedKeys = make([]string, strings.Count(strKeys, "["))
 	i := 0
 	for strings.Contains(strKeys, "[") {
-		var start, end = strings.Index(strKeys, "["), strings.Index(strKeys, "]")
-		if start == -1 || end == -1 {
-			err = errors.New("malformed key")
+		start := strings.Index(strKeys, "[")
+		// the closing bracket must follow the opening one, "a[x]]y[" is malformed
+		end := strings.Index(strKeys[start:], "]")
+		if end == -1 {
+			err = ErrMalformedKey
 			return
 		}
+		end += start
 		nestedKeys[i] = strKeys[start+1 : end]
 		strKeys = strKeys[end+1:]
 		i++
@@ -178,71 +231,19 @@ func getParseKey(key string) (root string, nestedKeys []string, err error) {
 //	  }
 //	}
 func ParseValues(src url.Values) (m Map, err error) {
-	var value, nestedKeys []string
-	var root string
-	var currentValue Value
-	var sIndex int = -1
-
-	out := newNilValue("").to(ValueMap)
-keyLoop:
-	for _, key := range sortUrlValues(src) {
-		value = src[key]
-		if root, nestedKeys, err = getParseKey(key); err != nil {
-			// missing [ or ]
-			// malformed input, ignoring value
-			continue keyLoop
-		}
-
-		var appendSlice = false
-		currentValue, _ = out.mapFor(root)
-		previousValue := currentValue
-		for _, keyPart := range nestedKeys {
-			appendSlice = false
-			previousValue = currentValue
-			if keyPart == "" {
-				appendSlice = true
-				currentValue = currentValue.to(ValueSlice)
-				// currentValue is a slice, but if the code reaches here, the next value will be a map.
-				// creates a slice element to host the new map element.
-				if currentValue, err = currentValue.newNilValueAt(-1); err != nil {
-					// cannot set slice value for keyPart
-					// malformed input, ignoring value
-					continue keyLoop
-				}
-			} else if sIndex, err = strconv.Atoi(keyPart); err == nil {
-				if !currentValue.cast(ValueSlice) {
-					// keyPart is an integer, so it is expected to be either nil or slice.
-					// malformed input, ignoring value
-					continue keyLoop
-				}
-				if currentValue, err = currentValue.to(ValueSlice).newNilValueAt(sIndex); err != nil {
-					// cannot set slice value for keyPart
-					// malformed input, ignoring value
-					continue keyLoop
-				}
-			} else if currentValue, err = currentValue.mapFor(keyPart); err != nil {
-				// cannot set map key value for keyPart
-				// malformed input, ignoring value
-				continue keyLoop
-			}
-		}
-
-		if appendSlice && previousValue.Is(ValueSlice) && len(value) > 1 {
-			currentValue.to(ValueString).setValue(value[0])
-			// value is a slice, creates elements
-			appendStrings(previousValue, -1, value[1:]...)
-			continue
-		}
-
-		if !currentValue.cast(ValueString) {
-			// cannot cast the current value
-			// malformed input, ignoring value
-			continue keyLoop
-		}
+	return ParseValuesWithOptions(src, ParseOptions{})
+}
 
-		currentValue.setValue(value[0])
+// ParseValuesWithOptions behaves as ParseValues, applying opts.
+// If a limit is exceeded parsing stops and a *LimitError is returned.
+// In strict mode the returned error is a *ParseErrors reporting each key that could not be parsed.
+func ParseValuesWithOptions(src url.Values, opts ParseOptions) (m Map, err error) {
+	keys := sortUrlValues(src)
+	fields := make([]field, len(keys))
+	for i, key := range keys {
+		fields[i] = field{key: key, values: src[key]}
 	}
-	return out, err
+	return newParser(opts).parse(fields)
 }
 
 type IterValue func(Value) error
@@ -275,14 +276,60 @@ type Map interface {
 	//  v, err := mapV.Get("input4", "key2", 1) // "input4[key2][1] = value4[key2][1]"
 	//  if v.Is(ValueString) { fmt.Printf("%s => %s", v.Key(), v.String()) }
 	//
+	//
+	// Errors are *PathError values wrapping ErrKeyNotFound, ErrValueNotMap, ErrValueNotSlice or ErrInvalidIndex.
 	GetValue(...any) (Value, error)
+	// GetPath() behaves as GetValue() but takes the keys as a bracket path, as returned by Value.Key().
+	//  v, err := mapV.GetPath("input4[key2][1]") // mapV.GetValue("input4", "key2", 1)
+	// See ParsePath() for details.
+	GetPath(path string) (Value, error)
+	// GetDotPath() behaves as GetPath() but takes the keys in dot notation.
+	//  v, err := mapV.GetDotPath("input4.key2.1") // mapV.GetValue("input4", "key2", 1)
+	GetDotPath(path string) (Value, error)
+	// Select() returns the values matching path, path uses the same int/string semantics as GetValue().
+	// A "*" segment matches every element of a ValueSlice and every key of a ValueMap,
+	// a "**" segment matches the value itself and all of its descendants.
+	// Values are returned once, in path order with map keys sorted, branches not matching path are ignored.
+	//  names := mapV.Select("rows", "*", "name") // rows[0][name], rows[1][name], ...
+	//  all := mapV.Select("**", "id")            // every "id" key in the tree
+	Select(path ...any) []Value
 	// GetString() behaves the same as Get(), ignore errors and returns "" if the value is not a string
 	// Note that GetString() will return "" also if the keys are correct but the string is an empty string.
 	//  mapV.Get("a", "b") // returns "" if a.b is not a string or an empty string.
 	GetString(keys ...any) string
 	// GetStrings() returns the content of a ValueSlice as a string slice.
-	// If the value is not a type ValueSlice, returns an empty slice. Any non-string values are ignored.
+	// If the value is not a type ValueSlice or ValueArray, returns an empty slice. Any non-string values are ignored.
 	GetStrings(keys ...any) []string
+	// GetInt() returns the ValueString at keys converted to int.
+	// If the lookup or the conversion fails it returns a *PathError holding the bracket path of the value.
+	GetInt(keys ...any) (int, error)
+	// GetIntOr() behaves as GetInt() but returns def if an error occurs.
+	GetIntOr(def int, keys ...any) int
+	// GetInt64() returns the ValueString at keys converted to int64, see GetInt().
+	GetInt64(keys ...any) (int64, error)
+	// GetInt64Or() behaves as GetInt64() but returns def if an error occurs.
+	GetInt64Or(def int64, keys ...any) int64
+	// GetUint() returns the ValueString at keys converted to uint, see GetInt().
+	GetUint(keys ...any) (uint, error)
+	// GetUintOr() behaves as GetUint() but returns def if an error occurs.
+	GetUintOr(def uint, keys ...any) uint
+	// GetFloat() returns the ValueString at keys converted to float64, see GetInt().
+	GetFloat(keys ...any) (float64, error)
+	// GetFloatOr() behaves as GetFloat() but returns def if an error occurs.
+	GetFloatOr(def float64, keys ...any) float64
+	// GetBool() returns the ValueString at keys converted to bool following HTML checkbox semantics, see GetInt().
+	// "1", "t", "true", "on" and "yes" are true, "", "0", "f", "false", "off" and "no" are false, case is ignored.
+	GetBool(keys ...any) (bool, error)
+	// GetBoolOr() behaves as GetBool() but returns def if an error occurs, ie an unchecked checkbox not submitted.
+	GetBoolOr(def bool, keys ...any) bool
+	// GetTime() returns the ValueString at keys parsed by time.Parse() using layout, see GetInt().
+	GetTime(layout string, keys ...any) (time.Time, error)
+	// GetTimeOr() behaves as GetTime() but returns def if an error occurs.
+	GetTimeOr(layout string, def time.Time, keys ...any) time.Time
+	// GetDuration() returns the ValueString at keys parsed by time.ParseDuration(), see GetInt().
+	GetDuration(keys ...any) (time.Duration, error)
+	// GetDurationOr() behaves as GetDuration() but returns def if an error occurs.
+	GetDurationOr(def time.Duration, keys ...any) time.Duration
 	// Returns a map containing Key/Values pair.
 	// Keys for array values are explicitly defined.
 	//  {
@@ -295,11 +342,53 @@ type Map interface {
 	//  "input3[4]": "value3[]"
 	//  }
 	KeyValue() map[string]string
-	// Iterate each key in a ValueMap or ValueSlice.
+	// Iterate each key in a ValueMap or ValueSlice, map keys are visited in sorted order.
 	// When called on ValueNil and ValueString, it does nothing but returns ErrValueNotMapOrSlice.
 	// When descending the keys Value.Key() returns the key relative to the position in the map.
-	// If each func(Value) error returns a non-nil value, Each() stops descending that path.
+	// If each func(Value) error returns a non-nil value, Each() stops and returns it, see Walk() for SkipChildren and StopWalk.
 	Each(each IterValue) error
+	// Walk() calls fn for each element of a ValueMap or ValueSlice and their descendants, in pre-order
+	// with map keys sorted. Returning SkipChildren skips the elements of the Value being visited,
+	// StopWalk stops the walk, any other error stops the walk and is returned by Walk().
+	//  err := mapV.Walk(func(path url.Path, v url.Value) error {
+	//  	if v.Is(url.ValueString) { fmt.Println(path, v.Key()) }
+	//  	return nil
+	//  })
+	Walk(fn WalkFunc) error
+	// WalkWithOptions() behaves as Walk(), opts selects post-order traversal and insertion ordered map keys.
+	WalkWithOptions(opts WalkOptions, fn WalkFunc) error
+	// All() returns an iterator over the elements of a ValueMap or ValueSlice and their descendants,
+	// in the same order as Walk().
+	//  for path, v := range mapV.All() { ... }
+	All() iter.Seq2[Path, Value]
+	// Leaves() behaves as All() but yields ValueString, ValueFile and ValueNil values only.
+	Leaves() iter.Seq2[Path, Value]
+	// Keys() returns an iterator over the keys of a ValueMap in insertion order, nothing is yielded for other types.
+	//  keys := slices.Collect(mapV.Keys())
+	Keys() iter.Seq[string]
+	// Elements() returns an iterator over the indices and elements of a ValueSlice, nothing is yielded for other types.
+	Elements() iter.Seq2[int, Value]
+	// Values() encodes the Map as url.Values using explicit slice indices, ValueNil elements are skipped.
+	//  "input3[0]": ["value3[0]"], "input4[key0]": ["value4[key0]"]
+	Values() url.Values
+	// Encode() encodes the Map as an application/x-www-form-urlencoded string, see EncodeOptions.
+	Encode(opts EncodeOptions) string
+	// MarshalJSON() encodes ValueMap as an object, ValueSlice as an array, ValueString as a string and ValueNil as null.
+	MarshalJSON() ([]byte, error)
+	// Set() stores value at path, path uses the same int/string semantics as GetValue().
+	// Missing elements are created: string keys create ValueMap, int keys create ValueSlice filling gaps with ValueNil.
+	// value can be nil (ValueNil), a string, a []string, a map[string]string, a *File or a Map, which is copied.
+	//  mapV.Set("Rome", "address", "city") // address[city]=Rome
+	//  mapV.Set([]string{"a", "b"}, "tags") // tags[0]=a&tags[1]=b
+	// If path crosses a Value of a different type a *PathError is returned.
+	Set(value any, path ...any) error
+	// Delete() removes the element at path, slice elements that follow are shifted and their keys updated.
+	Delete(path ...any) error
+	// Append() appends values to the ValueSlice or ValueArray at path, the slice is created if missing.
+	Append(path []any, values ...string) error
+	// Insert() inserts values in the ValueSlice at path before index, the slice is created if missing.
+	// index must be between 0 and the length of the slice.
+	Insert(path []any, index int, values ...string) error
 }
 
 type valueWriter interface {
@@ -318,10 +407,15 @@ type Value interface {
 	String() (value string, ok bool)
 	// returns the value as a []Value, fails if Type() != ValueSlice
 	Slice() (value []Value, ok bool)
-	// returns the value as a map[string]Value, fails if Type() != ValueMap
+	// returns the value as a *File, fails if Type() != ValueFile
+	File() (value *File, ok bool)
+	// returns a copy of the value as a map[string]Value, fails if Type() != ValueMap.
+	// Changes to the map do not affect the Value, use Set() and Delete(). StringKeys() returns its keys in insertion order.
 	Map() (value map[string]Value, ok bool)
 	// key of the item in the Map
 	Key() string
+	// part of the request the value was parsed from, see ParseRequest
+	Source() Source
 	// type of the value
 	Type() ValueType
 	// true if Type() matches t
@@ -331,7 +425,17 @@ type Value interface {
 	// ValueString returns the length of the string.
 	// ValueSlice returns the length of the slice.
 	// ValueMap returns the length of the map.
+	// ValueArray returns the number of keys.
+	// ValueFile returns the size of the file.
 	Len() int
+	// returns the int keys of a ValueArray in insertion order, the indices of a ValueSlice, nil otherwise
+	IntKeys() []int
+	// returns the keys of a ValueMap or the string keys of a ValueArray in insertion order, nil otherwise
+	StringKeys() []string
+	// returns the elements of a ValueSlice, ValueArray or ValueMap in order, nil otherwise
+	ToSlice() []Value
+	// returns the elements of a ValueMap, ValueArray or ValueSlice by key, int keys are formatted as strings, nil otherwise
+	ToMap() map[string]Value
 }
 
 type ValueType int
@@ -345,6 +449,10 @@ const (
 	ValueSlice
 	// string value
 	ValueString
+	// ordered hash holding both int and string keys, as PHP arrays, see ParseOptions.MixedKeys
+	ValueArray
+	// uploaded file, see ParseMultipart
+	ValueFile
 )
 
 func (vt ValueType) String() string {
@@ -355,6 +463,10 @@ func (vt ValueType) String() string {
 		return "ValueSlice"
 	case 3:
 		return "ValueString"
+	case 4:
+		return "ValueArray"
+	case 5:
+		return "ValueFile"
 	}
 	return "ValueNil"
 }
@@ -363,6 +475,7 @@ type item struct {
 	key       string
 	value     any
 	valueType ValueType
+	source    Source
 }
 
 func (val *item) setValue(v any) {
@@ -396,8 +509,19 @@ func (val *item) to(t ValueType) Value {
 	if t == ValueMap {
 		if !val.Is(ValueMap) {
 			val.valueType = ValueMap
-			var mapV = make(map[string]Value, 0)
-			val.value = &mapV
+			val.value = newOrderedMap()
+		}
+	}
+	if t == ValueArray {
+		if !val.Is(ValueArray) {
+			val.valueType = ValueArray
+			val.value = newArray()
+		}
+	}
+	if t == ValueFile {
+		if !val.Is(ValueFile) {
+			val.valueType = ValueFile
+			val.value = &File{}
 		}
 	}
 	return val
@@ -407,30 +531,13 @@ func (val *item) mapFor(k string) (Value, error) {
 	if !val.cast(ValueMap) {
 		return nil, ErrValueNotMap
 	}
-	valueKey := ""
-	if val.key == "" {
-		valueKey = k
-	} else {
-		valueKey = val.key + "[" + k + "]"
-	}
-	m := (val.value).(*map[string]Value)
-	if s, ok := (*m)[k]; ok {
+	m := (val.value).(*orderedMap)
+	if s, ok := m.get(k); ok {
 		return s, nil
-	} else {
-		(*m)[k] = newNilValue(valueKey)
 	}
-	return (*m)[k], nil
-}
-
-func appendStrings(val Value, sliceIndex int, list ...string) error {
-	if !val.Is(ValueSlice) {
-		return ErrValueNotSlice
-	}
-	for _, str := range list {
-		nested, _ := val.newNilValueAt(sliceIndex)
-		nested.to(ValueString).setValue(str)
-	}
-	return nil
+	v := newNilValue(childKey(val.key, k))
+	m.set(k, v)
+	return v, nil
 }
 
 func (val *item) newNilValueAt(sliceIndex int) (Value, error) {
@@ -442,8 +549,7 @@ func (val *item) newNilValueAt(sliceIndex int) (Value, error) {
 		sliceIndex = len(*slice)
 	}
 	for i := 0; sliceIndex >= len(*slice); i++ {
-		k := fmt.Sprintf("%s[%d]", val.key, len(*slice))
-		*slice = append(*slice, newNilValue(k))
+		*slice = append(*slice, newNilValue(elementKey(val.key, len(*slice))))
 	}
 	return (*slice)[sliceIndex], nil
 }
@@ -463,36 +569,12 @@ func newNilValue(key string) *item {
 	}
 }
 
-func (val *item) GetValue(keys ...any) (out Value, err error) {
-	if len(keys) == 0 {
-		return val, nil
-	}
-	out = val
-	path := ""
-	for kIndex, k := range keys {
-		path += out.Key()
-		if i, ok := k.(int); ok {
-			if !out.Is(ValueSlice) {
-				return newNilValue(path), fmt.Errorf("%s invalid key at pos:%d, Value not SliceValue found %s", path, kIndex, out.Type())
-			}
-			s, _ := out.Slice()
-			if i >= len(s) {
-				return newNilValue(path), fmt.Errorf("%s invalid key at pos:%d, index is out of range:%d", path, kIndex, i)
-			}
-			out = s[i]
-		} else if s, ok := k.(string); ok {
-			if !out.Is(ValueMap) {
-				return newNilValue(path), fmt.Errorf("%s invalid key at pos:%d, Value is not a MapValue found %s", path, kIndex, out.Type())
-			}
-			m, _ := out.Map()
-			if out, ok = m[s]; !ok {
-				return newNilValue(path), fmt.Errorf("%s invalid key at pos:%d, unknown key:%s", path, kIndex, s)
-			}
-		} else {
-			return newNilValue(path), fmt.Errorf("%s invalid key at pos:%d, expected int|string found %T", path, kIndex, k)
-		}
+func (val *item) GetValue(keys ...any) (Value, error) {
+	out, err := val.walkPath("GetValue", keys, false)
+	if err != nil {
+		return newNilValue(err.(*PathError).Path), err
 	}
-	return
+	return out, nil
 }
 
 func (val *item) GetString(keys ...any) string {
@@ -503,8 +585,8 @@ func (val *item) GetString(keys ...any) string {
 
 func (val *item) GetStrings(keys ...any) (slice []string) {
 	out, _ := val.GetValue(keys...)
-	if out.Is(ValueSlice) {
-		in, _ := out.Slice()
+	if out.Is(ValueSlice) || out.Is(ValueArray) {
+		in := out.ToSlice()
 		slice = make([]string, out.Len())
 		for i, v := range in {
 			slice[i], _ = v.String()
@@ -514,32 +596,12 @@ func (val *item) GetStrings(keys ...any) (slice []string) {
 }
 
 func (val *item) Each(each IterValue) error {
-	if val.Is(ValueMap) {
-		m, _ := (val.value).(*map[string]Value)
-		for _, v := range *m {
-			if err := each(v); err != nil {
-				return err
-			}
-			if v.IsNil() || v.Is(ValueString) {
-				continue
-			}
-			v.Each(each)
-		}
-		return nil
-	} else if val.Is(ValueSlice) {
-		s, _ := (val.value).(*[]Value)
-		for _, v := range *s {
-			if err := each(v); err != nil {
-				return err
-			}
-			if v.IsNil() || v.Is(ValueString) {
-				continue
-			}
-			v.Each(each)
-		}
-		return nil
+	if !val.Is(ValueMap) && !val.Is(ValueSlice) && !val.Is(ValueArray) {
+		return ErrValueNotMapOrSlice
 	}
-	return ErrValueNotMapOrSlice
+	return val.Walk(func(_ Path, v Value) error {
+		return each(v)
+	})
 }
 
 func (val *item) KeyValue() (out map[string]string) {
@@ -581,14 +643,26 @@ func (val *item) IsNil() bool {
 }
 
 func (val *item) Map() (value map[string]Value, ok bool) {
-	ptr, ok := (val.value).(*map[string]Value)
+	m, ok := (val.value).(*orderedMap)
 	if ok {
-		value = *ptr
+		value = maps.Clone(m.elements)
 	}
 	return
 
 }
 
+// returns the elements of the ValueMap val without copying them, the map must not be modified.
+func mapElements(val Value) (map[string]Value, bool) {
+	if it, ok := val.(*item); ok {
+		m, ok := it.value.(*orderedMap)
+		if !ok {
+			return nil, false
+		}
+		return m.elements, true
+	}
+	return val.Map()
+}
+
 func (val *item) Is(t ValueType) bool {
 	return val.valueType == t
 }
@@ -596,14 +670,20 @@ func (val *item) Is(t ValueType) bool {
 func (val *item) Len() int {
 	switch val.valueType {
 	case ValueMap:
-		v := (val.value).(*map[string]Value)
-		return len(*v)
+		v := (val.value).(*orderedMap)
+		return len(v.keys)
 	case ValueSlice:
 		v := (val.value).(*[]Value)
 		return len(*v)
 	case ValueString:
 		v := (val.value).(string)
 		return len(v)
+	case ValueArray:
+		v := (val.value).(*array)
+		return len(v.keys)
+	case ValueFile:
+		v := (val.value).(*File)
+		return int(v.Size)
 	}
 	return 0
 }
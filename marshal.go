@@ -0,0 +1,201 @@
+package url
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// MarshalError describes a Go value that cannot be encoded.
+type MarshalError struct {
+	// Path is the bracket key the value would have been encoded as
+	Path string
+	// Type is the Go type of the value
+	Type reflect.Type
+	// Err is the underlying error
+	Err error
+}
+
+func (e *MarshalError) Error() string {
+	return fmt.Sprintf("%s: cannot marshal %s: %v", e.Path, e.Type, e.Err)
+}
+
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Marshal encodes v into url.Values, v must be a struct or a map with string keys, or a pointer to them.
+//
+// Marshal uses the same `url:"name"` tags as Unmarshal, the "omitempty" option skips fields
+// holding false, 0, "", nil pointers and interfaces, and empty maps, slices and arrays.
+//
+//	type Form struct {
+//		Name    string            `url:"name"`
+//		Tags    []string          `url:"tags,omitempty"`
+//		Address *Address          `url:"address"`
+//		Meta    map[string]string `url:"meta"`
+//	}
+//
+//	// name=John & tags[0]=a & tags[1]=b & address[city]=Rome & meta[k]=v
+//	values, err := url.Marshal(Form{...})
+//
+// Nested structs and maps are encoded as "key[name]", slices and arrays as "key[index]",
+// map keys are sorted. Types implementing encoding.TextMarshaler are encoded as a single value,
+// time.Duration using its String() method. Nil pointers and interfaces are not encoded,
+// within a slice they result in a ValueNil element once parsed.
+//
+// The encoded values parse back through ParseValues and Unmarshal into the same struct.
+func Marshal(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map {
+		return nil, &MarshalError{Type: reflect.TypeOf(v), Err: fmt.Errorf("expected a struct or a map")}
+	}
+	out := make(url.Values)
+	if err := marshalValue(out, "", rv); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// encodes rv into out using key.
+func marshalValue(out url.Values, key string, rv reflect.Value) error {
+	if !rv.IsValid() {
+		return nil
+	}
+	if rv.Type().Implements(textMarshalerType) || (rv.CanAddr() && rv.Addr().Type().Implements(textMarshalerType)) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		if !rv.Type().Implements(textMarshalerType) {
+			rv = rv.Addr()
+		}
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return &MarshalError{Path: key, Type: rv.Type(), Err: err}
+		}
+		out.Add(key, string(text))
+		return nil
+	}
+	if rv.Type() == valueInterfaceType || rv.Type() == mapInterfaceType {
+		if rv.IsNil() {
+			return nil
+		}
+		val, err := rv.Interface().(Map).GetValue()
+		if err != nil {
+			return &MarshalError{Path: key, Type: rv.Type(), Err: err}
+		}
+		marshalTree(out, key, val)
+		return nil
+	}
+	if rv.Type() == durationType {
+		out.Add(key, time.Duration(rv.Int()).String())
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return marshalValue(out, key, rv.Elem())
+	case reflect.Struct:
+		for _, f := range structFields(rv.Type()) {
+			fv := rv.FieldByIndex(f.index)
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			if err := marshalValue(out, childKey(key, f.name), fv); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return &MarshalError{Path: key, Type: rv.Type(), Err: fmt.Errorf("map key is not a string")}
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			mk := reflect.ValueOf(k).Convert(rv.Type().Key())
+			if err := marshalValue(out, childKey(key, k), rv.MapIndex(mk)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			out.Add(key, string(rv.Bytes()))
+			return nil
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := marshalValue(out, fmt.Sprintf("%s[%d]", key, i), rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		out.Add(key, rv.String())
+	case reflect.Bool:
+		out.Add(key, strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		out.Add(key, strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		out.Add(key, strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		out.Add(key, strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()))
+	default:
+		return &MarshalError{Path: key, Type: rv.Type(), Err: fmt.Errorf("unsupported type")}
+	}
+	return nil
+}
+
+// encodes the strings found in val into out, using key as root.
+func marshalTree(out url.Values, key string, val Value) {
+	switch val.Type() {
+	case ValueString:
+		s, _ := val.String()
+		out.Add(key, s)
+	case ValueSlice:
+		s, _ := val.Slice()
+		for i, nested := range s {
+			marshalTree(out, fmt.Sprintf("%s[%d]", key, i), nested)
+		}
+	case ValueMap:
+		m, _ := mapElements(val)
+		for _, k := range sortedKeys(m) {
+			marshalTree(out, childKey(key, k), m[k])
+		}
+	case ValueArray:
+		for _, e := range entries(val, OrderInsertion) {
+			marshalTree(out, pathKey(key, e.key), e.value)
+		}
+	}
+}
+
+// reports whether rv holds the zero value for the omitempty option.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return rv.IsNil()
+	}
+	return false
+}
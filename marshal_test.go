@@ -0,0 +1,116 @@
+package url_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestMarshal(t *testing.T) {
+	raw, _ := URL.ParseValues(url.Values{"raw[a]": {"b"}})
+	rawValue, _ := raw.GetValue("raw")
+	form := testForm{
+		testEmbedded: testEmbedded{Source: "web"},
+		Name:         "John",
+		Age:          42,
+		Score:        9.5,
+		Subscribe:    true,
+		Tags:         []string{"a", "b"},
+		Pair:         [2]int{1, 2},
+		Address:      &testAddress{City: "Rome", Zip: 100},
+		Addresses:    []testAddress{{City: "Milan"}, {City: "Turin"}},
+		Meta:         map[string]string{"k1": "v1", "k2": "v2"},
+		Timeout:      90 * time.Second,
+		When:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:          rawValue,
+		Any:          []any{"x"},
+		Ignored:      "ignored",
+		Untagged:     "untagged",
+	}
+	values, err := URL.Marshal(&form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"source":             "web",
+		"name":               "John",
+		"age":                "42",
+		"subscribe":          "true",
+		"tags[1]":            "b",
+		"pair[0]":            "1",
+		"address[city]":      "Rome",
+		"addresses[1][city]": "Turin",
+		"addresses[1][zip]":  "0",
+		"meta[k2]":           "v2",
+		"timeout":            "1m30s",
+		"when":               "2024-01-02T03:04:05Z",
+		"raw[a]":             "b",
+		"any[0]":             "x",
+		"Untagged":           "untagged",
+	}
+	for k, v := range expected {
+		if values.Get(k) != v {
+			t.Errorf("%s: expected %q found %q", k, v, values.Get(k))
+		}
+	}
+	for _, k := range []string{"-", "Ignored", "missing"} {
+		if _, ok := values[k]; ok {
+			t.Errorf("%s: expected key not to be encoded", k)
+		}
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		var out testForm
+		if err := URL.UnmarshalValues(values, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Raw == nil || out.Raw.GetString("a") != "b" {
+			t.Error("raw: expected the ValueMap to round trip")
+		}
+		out.Raw, form.Raw, form.Ignored = nil, nil, ""
+		if !reflect.DeepEqual(out, form) {
+			t.Errorf("expected %+v\nfound %+v", form, out)
+		}
+	})
+
+	t.Run("omitempty", func(t *testing.T) {
+		values, err := URL.Marshal(struct {
+			A string         `url:"a,omitempty"`
+			B []int          `url:"b,omitempty"`
+			C *testAddress   `url:"c,omitempty"`
+			D map[string]int `url:"d,omitempty"`
+			E int            `url:"e"`
+		}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != 1 || values.Get("e") != "0" {
+			t.Errorf("expected only e to be encoded, found %v", values)
+		}
+	})
+
+	t.Run("nil slice elements", func(t *testing.T) {
+		values, _ := URL.Marshal(map[string][]*string{"list": {nil, new(string)}})
+		mapV, _ := URL.ParseValues(values)
+		if v, err := mapV.GetValue("list", 0); err != nil || !v.IsNil() {
+			t.Errorf("list[0]: expected ValueNil")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := URL.Marshal("string"); err == nil {
+			t.Error("expected an error for a non struct value")
+		}
+		_, err := URL.Marshal(struct {
+			C []chan int `url:"c"`
+		}{C: []chan int{nil}})
+		var merr *URL.MarshalError
+		if !errors.As(err, &merr) || merr.Path != "c[0]" {
+			t.Errorf("expected *MarshalError at c[0], found %v", err)
+		}
+	})
+}
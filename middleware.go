@@ -0,0 +1,157 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// MiddlewareOptions defines how Middleware parses requests and replies to the ones it cannot parse.
+type MiddlewareOptions struct {
+	RequestOptions
+	// ErrorHandler replies to requests ParseRequest returned an error for, and to the ones Handler cannot bind
+	// or validate. A nil ErrorHandler uses ErrorProblem.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	// NewStore creates the FileStore holding the files uploaded with a request, its Cleanup() is called once
+	// the request is handled. A nil NewStore keeps the files of each request in a new MemoryStore.
+	// RequestOptions.Store would be shared by every request and must not be set.
+	NewStore func() FileStore
+}
+
+// Problem is a JSON problem body as defined by RFC 9457, see WriteProblem.
+type Problem struct {
+	// Type is a URI identifying the problem, "about:blank" when Status is enough
+	Type string `json:"type"`
+	// Title is the status text of Status
+	Title string `json:"title"`
+	// Status is the HTTP status code
+	Status int `json:"status"`
+	// Detail describes the occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Errors lists the keys the problem relates to
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError describes the problem found at a key.
+type ProblemError struct {
+	// Path is the bracket path of the key, ie "address[city]"
+	Path string `json:"path"`
+	// Detail describes the problem
+	Detail string `json:"detail"`
+}
+
+type contextKey struct{}
+
+// Middleware parses each request with ParseRequest and stores the resulting Map in the request context,
+// handlers retrieve it with FromContext.
+//
+//	mux.Handle("/search", url.Middleware(url.MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		q := url.FromContext(r.Context()).GetString("q")
+//	})))
+//
+// The request is parsed once, if a Map is already stored in the context the request is not parsed again.
+// ParseRequest applies its default limits, a zero MiddlewareOptions rejects "?a[2000000]=x" with status 413.
+// If ParseRequest returns an error, including the decoding errors returned along with a Map when
+// ParseOptions.Strict is not set, opts.ErrorHandler replies and the next handler is not called.
+// Uploaded files are removed once the next handler returns, see MiddlewareOptions.NewStore.
+//
+// Middleware panics if opts.Store is set.
+func Middleware(opts MiddlewareOptions) func(http.Handler) http.Handler {
+	onError := opts.errorHandler()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if FromContext(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			m, store, err := parseRequest(r, opts)
+			defer store.Cleanup()
+			if err != nil {
+				onError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), m)))
+		})
+	}
+}
+
+// returns the ErrorHandler of opts, panics if opts.Store is set.
+func (opts MiddlewareOptions) errorHandler() func(w http.ResponseWriter, r *http.Request, err error) {
+	if opts.Store != nil {
+		panic("url: MiddlewareOptions.Store is shared by every request, set NewStore instead")
+	}
+	if opts.ErrorHandler == nil {
+		return ErrorProblem
+	}
+	return opts.ErrorHandler
+}
+
+// parses r storing its files into a FileStore created for the request, the caller cleans the store up.
+func parseRequest(r *http.Request, opts MiddlewareOptions) (m Map, store FileStore, err error) {
+	if opts.NewStore != nil {
+		store = opts.NewStore()
+	} else {
+		store = &MemoryStore{}
+	}
+	ropts := opts.RequestOptions
+	ropts.Store = store
+	m, err = ParseRequest(r, ropts)
+	return m, store, err
+}
+
+// NewContext returns a copy of ctx holding m.
+func NewContext(ctx context.Context, m Map) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the Map stored in ctx by Middleware, nil if ctx holds no Map.
+func FromContext(ctx context.Context) Map {
+	m, _ := ctx.Value(contextKey{}).(Map)
+	return m
+}
+
+// ErrorProblem replies to a request that cannot be handled because of err, it is the default MiddlewareOptions.ErrorHandler.
+//
+// Limit errors are reported with status 413, *ValidationErrors and *UnmarshalError with status 422, other errors with status 400.
+// The keys reported by a *ParseErrors, a *ValidationErrors or an *UnmarshalError are listed in Problem.Errors.
+func ErrorProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := Problem{Status: http.StatusBadRequest, Detail: err.Error()}
+	var perrs *ParseErrors
+	var verrs *ValidationErrors
+	var uerr *UnmarshalError
+	switch {
+	case errors.Is(err, ErrLimitExceeded):
+		p.Status = http.StatusRequestEntityTooLarge
+	case errors.As(err, &perrs):
+		p.Detail = "request parameters cannot be parsed"
+		for _, perr := range perrs.Errors {
+			p.Errors = append(p.Errors, ProblemError{Path: perr.Key, Detail: perr.Reason.String()})
+		}
+	case errors.As(err, &verrs):
+		p.Status = http.StatusUnprocessableEntity
+		p.Detail = "request parameters are not valid"
+		for _, ferr := range verrs.Errors {
+			p.Errors = append(p.Errors, ProblemError{Path: ferr.Path, Detail: ferr.Err.Error()})
+		}
+	case errors.As(err, &uerr):
+		p.Status = http.StatusUnprocessableEntity
+		p.Detail = "request parameters are not valid"
+		p.Errors = []ProblemError{{Path: uerr.Path, Detail: uerr.Err.Error()}}
+	}
+	WriteProblem(w, p)
+}
+
+// WriteProblem writes p as an application/problem+json response, empty Type and Title are set from Status.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,180 @@
+package url_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func testProblem(t *testing.T, rec *httptest.ResponseRecorder) URL.Problem {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected application/problem+json, found %q", ct)
+	}
+	var p URL.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	var got URL.Map
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		got = URL.FromContext(r.Context())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		calls = 0
+		h := URL.Middleware(URL.MiddlewareOptions{})(next)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, testFormRequest("/?q=go", "filters[lang]=en"))
+		if calls != 1 || rec.Code != http.StatusOK {
+			t.Fatalf("expected handler to be called, found %d calls and status %d", calls, rec.Code)
+		}
+		if q := got.GetString("q"); q != "go" {
+			t.Errorf("q: expected go, found %q", q)
+		}
+		if lang := got.GetString("filters", "lang"); lang != "en" {
+			t.Errorf("filters[lang]: expected en, found %q", lang)
+		}
+	})
+	t.Run("parse once", func(t *testing.T) {
+		calls = 0
+		mw := URL.Middleware(URL.MiddlewareOptions{})
+		var first URL.Map
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			first = URL.FromContext(r.Context())
+			mw(next).ServeHTTP(w, r)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), testFormRequest("/", "a=1"))
+		if calls != 1 || got != first {
+			t.Error("expected the Map of the outer middleware")
+		}
+	})
+	t.Run("bad request", func(t *testing.T) {
+		calls = 0
+		h := URL.Middleware(URL.MiddlewareOptions{})(next)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, testFormRequest("/", "a=%zz"))
+		if calls != 0 || rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, found %d calls and status %d", calls, rec.Code)
+		}
+		if p := testProblem(t, rec); p.Status != 400 || p.Title != "Bad Request" || p.Type != "about:blank" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		opts := URL.MiddlewareOptions{}
+		opts.Strict = true
+		rec := httptest.NewRecorder()
+		URL.Middleware(opts)(next).ServeHTTP(rec, testFormRequest("/?a[0]=1&a[x]=2", ""))
+		p := testProblem(t, rec)
+		if rec.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Path != "a[x]" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("limit", func(t *testing.T) {
+		calls = 0
+		opts := URL.MiddlewareOptions{RequestOptions: URL.RequestOptions{MaxBodyBytes: 8}}
+		rec := httptest.NewRecorder()
+		URL.Middleware(opts)(next).ServeHTTP(rec, testFormRequest("/", "a="+strings.Repeat("x", 64)))
+		if calls != 0 || rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected status 413, found %d calls and status %d", calls, rec.Code)
+		}
+		if p := testProblem(t, rec); p.Status != 413 {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("default limits", func(t *testing.T) {
+		calls = 0
+		rec := httptest.NewRecorder()
+		URL.Middleware(URL.MiddlewareOptions{})(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?a[2000000]=x", nil))
+		if calls != 0 || rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected status 413, found %d calls and status %d", calls, rec.Code)
+		}
+		if p := testProblem(t, rec); p.Status != 413 || !strings.Contains(p.Detail, "MaxSliceIndex") {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	})
+	t.Run("error handler", func(t *testing.T) {
+		opts := URL.MiddlewareOptions{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, "custom", http.StatusTeapot)
+		}}
+		rec := httptest.NewRecorder()
+		URL.Middleware(opts)(next).ServeHTTP(rec, testFormRequest("/", "a=%zz"))
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("expected status 418, found %d", rec.Code)
+		}
+	})
+	t.Run("store", func(t *testing.T) {
+		upload := func(content string) *http.Request {
+			buf, boundary := testMultipart(t, [3]string{"f", "f.txt", content})
+			r := httptest.NewRequest(http.MethodPost, "/", buf)
+			r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+			return r
+		}
+		var dirs []string
+		opts := URL.MiddlewareOptions{NewStore: func() URL.FileStore {
+			store, err := URL.NewTempDirStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			dirs = append(dirs, store.Dir())
+			return store
+		}}
+		var content string
+		h := URL.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v, _ := URL.FromContext(r.Context()).GetValue("f")
+			content = testFileContent(t, v)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), upload("first"))
+		h.ServeHTTP(httptest.NewRecorder(), upload("second"))
+		if content != "second" || len(dirs) != 2 {
+			t.Fatalf("expected a store per request, found %d stores", len(dirs))
+		}
+		for _, dir := range dirs {
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed, found %v", dir, err)
+			}
+		}
+
+		opts = URL.MiddlewareOptions{NewStore: func() URL.FileStore { return &URL.MemoryStore{MaxBytes: 12} }}
+		h = URL.Middleware(opts)(next)
+		for i := 0; i < 3; i++ {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, upload("0123456789"))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("upload %d: expected status 200, found %d", i, rec.Code)
+			}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, upload(strings.Repeat("x", 20)))
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status 413, found %d", rec.Code)
+		}
+	})
+	t.Run("shared store", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Middleware to panic")
+			}
+		}()
+		opts := URL.MiddlewareOptions{}
+		opts.Store = &URL.MemoryStore{}
+		URL.Middleware(opts)
+	})
+	t.Run("no map", func(t *testing.T) {
+		if URL.FromContext(context.Background()) != nil {
+			t.Error("expected nil Map")
+		}
+	})
+}
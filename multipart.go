@@ -0,0 +1,219 @@
+package url
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+)
+
+// File is an uploaded file held by a ValueFile.
+type File struct {
+	// Filename is the name of the file as submitted by the client, it must not be trusted as a path.
+	Filename string
+	// Header is the MIME header of the part, ie Content-Type
+	Header textproto.MIMEHeader
+	// Size is the size of the file in bytes
+	Size int64
+	// ContentType is the content type detected by the SniffContentType hook
+	ContentType string
+	// SHA256 is the checksum computed by the SHA256Checksum hook
+	SHA256 []byte
+	open   func() (multipart.File, error)
+}
+
+// Open opens the content of the file.
+func (f *File) Open() (multipart.File, error) {
+	if f.open == nil {
+		return nil, errors.New("file has no content")
+	}
+	return f.open()
+}
+
+func (val *item) File() (value *File, ok bool) {
+	value, ok = val.value.(*File)
+	return
+}
+
+// MultipartOptions defines the ParseOptions and the size limits applied to multipart forms.
+// A zero or negative limit is not enforced.
+type MultipartOptions struct {
+	ParseOptions
+	// MaxFileBytes is the maximum size of a single file.
+	MaxFileBytes int64
+	// MaxTotalBytes is the maximum size of the form, text fields and files.
+	MaxTotalBytes int64
+	// Store stores the files read by ParseMultipartReader, a nil Store keeps them in memory.
+	Store FileStore
+	// Hooks observe the content of each file as it is read, ie SniffContentType() and SHA256Checksum().
+	Hooks []FileHook
+}
+
+// ParseMultipart parses a multipart form, text fields and files are merged into a single Map, files become ValueFile.
+//
+//	// documents[0][title]=Invoice, documents[0][file]=@invoice.pdf
+//	r.ParseMultipartForm(32 << 20)
+//	mapV, err := url.ParseMultipart(r.MultipartForm)
+//	v, err := mapV.GetValue("documents", 0, "file")
+//	file, ok := v.File()
+//
+// Keys are processed in sorted order as ParseValues does, text fields before files.
+func ParseMultipart(form *multipart.Form) (m Map, err error) {
+	return ParseMultipartWithOptions(form, MultipartOptions{})
+}
+
+// ParseMultipartWithOptions behaves as ParseMultipart, applying opts.
+// If a file or the form exceed the size limits a *LimitError is returned.
+// Files are already stored by the form, opts.Store is not used while opts.Hooks read the content of each file.
+func ParseMultipartWithOptions(form *multipart.Form, opts MultipartOptions) (m Map, err error) {
+	var fields []field
+	var total int64
+	for _, key := range sortUrlValues(form.Value) {
+		for _, s := range form.Value[key] {
+			total += int64(len(s))
+		}
+		fields = append(fields, field{key: key, values: form.Value[key]})
+	}
+	keys := make([]string, 0, len(form.File))
+	for key := range form.File {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		f := field{key: key}
+		for _, fh := range form.File[key] {
+			if exceeds64(fh.Size, opts.MaxFileBytes) {
+				return nil, &LimitError{Limit: "MaxFileBytes", Max: int(opts.MaxFileBytes), Key: key}
+			}
+			total += fh.Size
+			file := &File{Filename: fh.Filename, Header: fh.Header, Size: fh.Size, open: fh.Open}
+			if err := observeFile(file, opts.Hooks); err != nil {
+				return nil, err
+			}
+			f.files = append(f.files, file)
+		}
+		fields = append(fields, f)
+	}
+	if exceeds64(total, opts.MaxTotalBytes) {
+		return nil, &LimitError{Limit: "MaxTotalBytes", Max: int(opts.MaxTotalBytes), Key: ""}
+	}
+	return newParser(opts.ParseOptions).parse(fields)
+}
+
+// ParseMultipartReader reads a multipart/form-data payload from r and parses it as ParseMultipart does,
+// boundary is the boundary parameter of the Content-Type header.
+//
+// Unlike ParseMultipart parts are processed in the order they were submitted and files are streamed into opts.Store,
+// each part is parsed as soon as it is read and reading stops as soon as a limit is exceeded.
+// ErrStoreFull returned by opts.Store is reported as a *LimitError with Limit "Store".
+func ParseMultipartReader(r io.Reader, boundary string, opts MultipartOptions) (m Map, err error) {
+	store := opts.Store
+	if store == nil {
+		store = &MemoryStore{}
+	}
+	mr := multipart.NewReader(r, boundary)
+	p := newParser(opts.ParseOptions)
+	out := newNilValue("").to(ValueMap)
+	var total int64
+	for i := 0; ; {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		key := part.FormName()
+		if key == "" {
+			continue
+		}
+		if exceeds(i+1, opts.MaxKeys) {
+			// stops before the content of the part is read
+			return nil, p.next(out, i, field{key: key})
+		}
+		// remaining bytes the part can hold, -1 if not limited
+		remaining, limitErr := int64(-1), (*LimitError)(nil)
+		if opts.MaxTotalBytes > 0 {
+			remaining = opts.MaxTotalBytes - total
+			limitErr = &LimitError{Limit: "MaxTotalBytes", Max: int(opts.MaxTotalBytes), Key: key}
+		}
+		if part.FileName() != "" && opts.MaxFileBytes > 0 && (remaining < 0 || opts.MaxFileBytes < remaining) {
+			remaining = opts.MaxFileBytes
+			limitErr = &LimitError{Limit: "MaxFileBytes", Max: int(opts.MaxFileBytes), Key: key}
+		}
+		src := io.Reader(part)
+		if remaining >= 0 {
+			src = io.LimitReader(part, remaining+1)
+		}
+		var f field
+		if part.FileName() == "" {
+			if opts.MaxValueBytes > 0 {
+				// a value longer than MaxValueBytes is reported by the parser
+				src = io.LimitReader(src, int64(opts.MaxValueBytes)+1)
+			}
+			var buf bytes.Buffer
+			n, err := buf.ReadFrom(src)
+			if err != nil {
+				return nil, err
+			}
+			if remaining >= 0 && n > remaining {
+				return nil, limitErr
+			}
+			total += n
+			f = field{key: key, values: []string{buf.String()}}
+		} else {
+			file := &File{Filename: part.FileName(), Header: part.Header}
+			if err := storeFile(file, src, store, opts.Hooks); errors.Is(err, ErrStoreFull) {
+				return nil, &LimitError{Limit: "Store", Key: key, Err: err}
+			} else if err != nil {
+				return nil, err
+			}
+			if remaining >= 0 && file.Size > remaining {
+				return nil, limitErr
+			}
+			total += file.Size
+			f = field{key: key, files: []*File{file}}
+		}
+		if err := p.next(out, i, f); err != nil {
+			return nil, err
+		}
+		i++
+	}
+	return p.result(out)
+}
+
+// reads the content of file applying hooks.
+func observeFile(file *File, hooks []FileHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	open := file.open
+	if err := storeFile(file, f, discardStore{}, hooks); err != nil {
+		return err
+	}
+	file.open = open
+	return nil
+}
+
+// discardStore is a FileStore discarding the content.
+type discardStore struct{}
+
+func (discardStore) Save(file *File, r io.Reader) (func() (multipart.File, error), error) {
+	_, err := io.Copy(io.Discard, r)
+	return nil, err
+}
+
+func (discardStore) Cleanup() error {
+	return nil
+}
+
+// returns true if limit is enforced and n is above it.
+func exceeds64(n, limit int64) bool {
+	return limit > 0 && n > limit
+}
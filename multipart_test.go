@@ -0,0 +1,210 @@
+package url_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+// returns a multipart payload holding fields and files, in the order given, and its boundary.
+func testMultipart(t *testing.T, parts ...[3]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		if p[1] == "" {
+			if err := w.WriteField(p[0], p[2]); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		fw, err := w.CreateFormFile(p[0], p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(fw, p[2])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf, w.Boundary()
+}
+
+func testFileContent(t *testing.T, v URL.Value) string {
+	t.Helper()
+	f, ok := v.File()
+	if !ok {
+		t.Fatalf("%s: expected ValueFile found %s", v.Key(), v.Type())
+	}
+	r, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestParseMultipart(t *testing.T) {
+	parts := [][3]string{
+		{"documents[0][title]", "", "Invoice"},
+		{"documents[0][file]", "invoice.pdf", "%PDF-invoice"},
+		{"documents[1][title]", "", "Receipt"},
+		{"documents[1][file]", "receipt.pdf", "%PDF-receipt"},
+		{"photos[]", "a.png", "png-a"},
+		{"photos[]", "b.png", "png-b"},
+	}
+
+	check := func(t *testing.T, mapV URL.Map) {
+		if s := mapV.GetString("documents", 1, "title"); s != "Receipt" {
+			t.Errorf("documents[1][title]: expected Receipt found %q", s)
+		}
+		v, err := mapV.GetValue("documents", 0, "file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.Is(URL.ValueFile) || v.Key() != "documents[0][file]" {
+			t.Fatalf("expected ValueFile at documents[0][file] found %s at %s", v.Type(), v.Key())
+		}
+		f, _ := v.File()
+		if f.Filename != "invoice.pdf" || f.Size != 12 || v.Len() != 12 || f.Header.Get("Content-Type") != "application/octet-stream" {
+			t.Errorf("unexpected file %+v", f)
+		}
+		if s := testFileContent(t, v); s != "%PDF-invoice" {
+			t.Errorf("unexpected content %q", s)
+		}
+		photos, _ := mapV.GetValue("photos")
+		if photos.Len() != 2 {
+			t.Fatalf("photos: expected 2 files found %d", photos.Len())
+		}
+		second, _ := mapV.GetValue("photos", 1)
+		if s := testFileContent(t, second); s != "png-b" {
+			t.Errorf("photos[1]: unexpected content %q", s)
+		}
+		if s := mapV.Encode(URL.EncodeOptions{}); strings.Contains(s, "file") {
+			t.Errorf("Encode: expected files to be skipped, found %s", s)
+		}
+	}
+
+	t.Run("form", func(t *testing.T) {
+		buf, boundary := testMultipart(t, parts...)
+		form, err := multipart.NewReader(buf, boundary).ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer form.RemoveAll()
+		mapV, err := URL.ParseMultipart(form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, mapV)
+
+		if _, err := URL.ParseMultipartWithOptions(form, URL.MultipartOptions{MaxFileBytes: 8}); !errors.Is(err, URL.ErrLimitExceeded) {
+			t.Errorf("expected ErrLimitExceeded, found %v", err)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		buf, boundary := testMultipart(t, parts...)
+		mapV, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, mapV)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		tests := []struct {
+			opts  URL.MultipartOptions
+			limit string
+		}{
+			{URL.MultipartOptions{MaxFileBytes: 11}, "MaxFileBytes"},
+			{URL.MultipartOptions{MaxTotalBytes: 30}, "MaxTotalBytes"},
+			{URL.MultipartOptions{MaxFileBytes: 100, MaxTotalBytes: 20}, "MaxTotalBytes"},
+			{URL.MultipartOptions{ParseOptions: URL.ParseOptions{MaxValueBytes: 4}}, "MaxValueBytes"},
+		}
+		for _, test := range tests {
+			buf, boundary := testMultipart(t, parts...)
+			_, err := URL.ParseMultipartReader(buf, boundary, test.opts)
+			var limitErr *URL.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != test.limit {
+				t.Errorf("expected %s found %v", test.limit, err)
+			}
+		}
+		buf, boundary := testMultipart(t, parts...)
+		if _, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{MaxFileBytes: 12, MaxTotalBytes: 60}); err != nil {
+			t.Errorf("expected no error found %v", err)
+		}
+	})
+
+	t.Run("stop reading", func(t *testing.T) {
+		files := make([][3]string, 500)
+		for i := range files {
+			files[i] = [3]string{"f[]", "f.txt", "content"}
+		}
+		buf, boundary := testMultipart(t, files...)
+		store, err := URL.NewTempDirStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Cleanup()
+		opts := URL.MultipartOptions{ParseOptions: URL.ParseOptions{MaxKeys: 2}, Store: store}
+		var limitErr *URL.LimitError
+		if _, err := URL.ParseMultipartReader(buf, boundary, opts); !errors.As(err, &limitErr) || limitErr.Limit != "MaxKeys" {
+			t.Fatalf("expected MaxKeys found %v", err)
+		}
+		if entries, _ := os.ReadDir(store.Dir()); len(entries) != 2 {
+			t.Errorf("expected 2 stored files, found %d", len(entries))
+		}
+
+		buf, boundary = testMultipart(t, [3]string{"text", "", strings.Repeat("x", 1<<20)})
+		size := buf.Len()
+		opts = URL.MultipartOptions{ParseOptions: URL.ParseOptions{MaxValueBytes: 4}}
+		if _, err := URL.ParseMultipartReader(buf, boundary, opts); !errors.As(err, &limitErr) || limitErr.Limit != "MaxValueBytes" {
+			t.Fatalf("expected MaxValueBytes found %v", err)
+		}
+		if read := size - buf.Len(); read > 1<<16 {
+			t.Errorf("expected the value not to be read whole, %d bytes read", read)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		buf, boundary := testMultipart(t, parts...)
+		mapV, err := URL.ParseMultipartReader(buf, boundary, URL.MultipartOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var form struct {
+			Documents []struct {
+				Title string    `url:"title"`
+				File  *URL.File `url:"file"`
+			} `url:"documents"`
+			Photos []URL.File `url:"photos"`
+		}
+		if err := URL.Unmarshal(mapV, &form); err != nil {
+			t.Fatal(err)
+		}
+		if len(form.Documents) != 2 || form.Documents[1].File.Filename != "receipt.pdf" || form.Photos[0].Filename != "a.png" {
+			t.Errorf("unexpected %+v", form)
+		}
+		var invalid struct {
+			Photos []string `url:"photos"`
+		}
+		if err := URL.Unmarshal(mapV, &invalid); err == nil {
+			t.Error("expected an error storing a file into a string")
+		}
+		b, _ := mapV.MarshalJSON()
+		if !strings.Contains(string(b), `"photos":[{"filename":"a.png","size":5},{"filename":"b.png","size":5}]`) {
+			t.Errorf("MarshalJSON: unexpected %s", b)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package url
+
+import (
+	"fmt"
+	"sort"
+)
+
+// returns the element of val addressed by k, int for ValueSlice, string for ValueMap and either for ValueArray.
+// When create is set missing elements are created and ValueNil is converted to the expected type.
+func (val *item) child(k any, create bool) (*item, error) {
+	if a, ok := val.value.(*array); ok {
+		switch k.(type) {
+		case int, string:
+		default:
+			return nil, fmt.Errorf("expected int|string found %T", k)
+		}
+		if create {
+			v, _ := val.arrayFor(k)
+			return v.(*item), nil
+		}
+		v, ok := a.get(k)
+		if !ok {
+			return nil, ErrKeyNotFound
+		}
+		return v.(*item), nil
+	}
+	switch k := k.(type) {
+	case int:
+		if k < 0 {
+			return nil, ErrInvalidIndex
+		}
+		if create {
+			if !val.cast(ValueSlice) {
+				return nil, ErrValueNotSlice
+			}
+			v, err := val.newNilValueAt(k)
+			if err != nil {
+				return nil, err
+			}
+			return v.(*item), nil
+		}
+		s, ok := val.Slice()
+		if !ok {
+			return nil, ErrValueNotSlice
+		}
+		if k >= len(s) {
+			return nil, ErrKeyNotFound
+		}
+		return s[k].(*item), nil
+	case string:
+		if create {
+			v, err := val.mapFor(k)
+			if err != nil {
+				return nil, err
+			}
+			return v.(*item), nil
+		}
+		m, ok := mapElements(val)
+		if !ok {
+			return nil, ErrValueNotMap
+		}
+		v, ok := m[k]
+		if !ok {
+			return nil, ErrKeyNotFound
+		}
+		return v.(*item), nil
+	}
+	return nil, fmt.Errorf("expected int|string found %T", k)
+}
+
+// returns the key of the element of val addressed by k.
+func pathKey(parent string, k any) string {
+	if i, ok := k.(int); ok {
+		return elementKey(parent, i)
+	}
+	return childKey(parent, fmt.Sprint(k))
+}
+
+// descends val following path, see child().
+func (val *item) walkPath(op string, path []any, create bool) (*item, error) {
+	current := val
+	for _, k := range path {
+		next, err := current.child(k, create)
+		if err != nil {
+			return nil, &PathError{Op: op, Path: pathKey(current.key, k), Err: err}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// replaces the content of val with value.
+func (val *item) assign(value any) error {
+	switch v := value.(type) {
+	case nil:
+		val.valueType, val.value = ValueNil, ""
+	case string:
+		val.valueType = ValueNil
+		val.to(ValueString).setValue(v)
+	case []string:
+		val.valueType = ValueNil
+		val.to(ValueSlice)
+		for _, s := range v {
+			nested, _ := val.newNilValueAt(-1)
+			nested.to(ValueString).setValue(s)
+		}
+	case map[string]string:
+		val.valueType = ValueNil
+		val.to(ValueMap)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			nested, _ := val.mapFor(k)
+			nested.to(ValueString).setValue(v[k])
+		}
+	case *File:
+		val.valueType = ValueNil
+		val.to(ValueFile).setValue(v)
+	case Map:
+		src, err := v.GetValue()
+		if err != nil {
+			return err
+		}
+		c := copyValue(src, val.key)
+		val.valueType, val.value = c.valueType, c.value
+	default:
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	return nil
+}
+
+// returns a deep copy of val using key as root key.
+func copyValue(val Value, key string) *item {
+	out := newNilValue(key)
+	switch val.Type() {
+	case ValueString:
+		s, _ := val.String()
+		out.to(ValueString).setValue(s)
+	case ValueSlice:
+		out.to(ValueSlice)
+		slice := out.value.(*[]Value)
+		for _, e := range entries(val, OrderInsertion) {
+			*slice = append(*slice, copyValue(e.value, elementKey(key, e.key.(int))))
+		}
+	case ValueMap:
+		out.to(ValueMap)
+		m := out.value.(*orderedMap)
+		for _, e := range entries(val, OrderInsertion) {
+			k := e.key.(string)
+			m.set(k, copyValue(e.value, childKey(key, k)))
+		}
+	case ValueFile:
+		f, _ := val.File()
+		out.to(ValueFile).setValue(f)
+	case ValueArray:
+		out.to(ValueArray)
+		a := out.value.(*array)
+		for _, e := range entries(val, OrderInsertion) {
+			a.set(e.key, copyValue(e.value, pathKey(key, e.key)))
+		}
+	}
+	out.source = val.Source()
+	return out
+}
+
+// updates the key of val and of its elements.
+func rekey(val *item, key string) {
+	val.key = key
+	for _, e := range entries(val, OrderInsertion) {
+		rekey(e.value.(*item), pathKey(key, e.key))
+	}
+}
+
+func (val *item) Set(value any, path ...any) error {
+	if len(path) == 0 {
+		return &PathError{Op: "set", Path: val.key, Err: ErrEmptyPath}
+	}
+	target, err := val.walkPath("set", path, true)
+	if err != nil {
+		return err
+	}
+	if err := target.assign(value); err != nil {
+		return &PathError{Op: "set", Path: target.key, Err: err}
+	}
+	return nil
+}
+
+func (val *item) Delete(path ...any) error {
+	if len(path) == 0 {
+		return &PathError{Op: "delete", Path: val.key, Err: ErrEmptyPath}
+	}
+	parent, err := val.walkPath("delete", path[:len(path)-1], false)
+	if err != nil {
+		return err
+	}
+	k := path[len(path)-1]
+	if _, err := parent.child(k, false); err != nil {
+		return &PathError{Op: "delete", Path: pathKey(parent.key, k), Err: err}
+	}
+	if a, ok := parent.value.(*array); ok {
+		// ValueArray keys are not renumbered, as PHP unset()
+		a.delete(k)
+		return nil
+	}
+	if i, ok := k.(int); ok {
+		slice := parent.value.(*[]Value)
+		*slice = append((*slice)[:i], (*slice)[i+1:]...)
+		for ; i < len(*slice); i++ {
+			rekey((*slice)[i].(*item), elementKey(parent.key, i))
+		}
+		return nil
+	}
+	parent.value.(*orderedMap).delete(k.(string))
+	return nil
+}
+
+func (val *item) Append(path []any, values ...string) error {
+	target, err := val.walkPath("append", path, true)
+	if err != nil {
+		return err
+	}
+	if target.Is(ValueArray) {
+		for _, s := range values {
+			nested, _ := target.arrayFor(nil)
+			nested.to(ValueString).setValue(s)
+		}
+		return nil
+	}
+	if !target.cast(ValueSlice) {
+		return &PathError{Op: "append", Path: target.key, Err: ErrValueNotSlice}
+	}
+	for _, s := range values {
+		nested, _ := target.newNilValueAt(-1)
+		nested.to(ValueString).setValue(s)
+	}
+	return nil
+}
+
+func (val *item) Insert(path []any, index int, values ...string) error {
+	target, err := val.walkPath("insert", path, true)
+	if err != nil {
+		return err
+	}
+	if !target.cast(ValueSlice) {
+		return &PathError{Op: "insert", Path: target.key, Err: ErrValueNotSlice}
+	}
+	slice := target.value.(*[]Value)
+	if index < 0 || index > len(*slice) {
+		return &PathError{Op: "insert", Path: elementKey(target.key, index), Err: ErrInvalidIndex}
+	}
+	inserted := make([]Value, len(values))
+	for i, s := range values {
+		nested := newNilValue("")
+		nested.to(ValueString).setValue(s)
+		inserted[i] = nested
+	}
+	*slice = append((*slice)[:index], append(inserted, (*slice)[index:]...)...)
+	for i := index; i < len(*slice); i++ {
+		rekey((*slice)[i].(*item), elementKey(target.key, i))
+	}
+	return nil
+}
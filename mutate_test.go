@@ -0,0 +1,137 @@
+package url_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestMutate(t *testing.T) {
+	mapV, err := URL.ParseQuery("rows[0][name]=a&rows[1][name]=b&rows[2][name]=c&s=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		if err := mapV.Set("Rome", "address", "city"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Set([]string{"a", "b"}, "tags"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Set(map[string]string{"k": "v"}, "meta", 2); err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("address", "city"); s != "Rome" {
+			t.Errorf("address[city]: expected Rome found %q", s)
+		}
+		if s := strings.Join(mapV.GetStrings("tags"), ","); s != "a,b" {
+			t.Errorf("tags: expected a,b found %q", s)
+		}
+		v, err := mapV.GetValue("meta", 2, "k")
+		if err != nil || v.Key() != "meta[2][k]" {
+			t.Errorf("meta[2][k]: unexpected %v (%v)", v, err)
+		}
+		if v, _ := mapV.GetValue("meta", 0); !v.IsNil() {
+			t.Errorf("meta[0]: expected ValueNil")
+		}
+		if err := mapV.Set(nil, "tags", 1); err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := mapV.GetValue("tags", 1); !v.IsNil() {
+			t.Errorf("tags[1]: expected ValueNil")
+		}
+	})
+
+	t.Run("set copies values", func(t *testing.T) {
+		rows, _ := mapV.GetValue("rows")
+		if err := mapV.Set(rows, "copy", "rows"); err != nil {
+			t.Fatal(err)
+		}
+		v, err := mapV.GetValue("copy", "rows", 1, "name")
+		if err != nil || v.Key() != "copy[rows][1][name]" {
+			t.Fatalf("copy[rows][1][name]: unexpected %v (%v)", v, err)
+		}
+		mapV.Set("changed", "copy", "rows", 1, "name")
+		if s := mapV.GetString("rows", 1, "name"); s != "b" {
+			t.Errorf("rows[1][name]: expected the original to be untouched, found %q", s)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		var tests = []struct {
+			path []any
+			err  error
+			key  string
+		}{
+			{[]any{"s", "sub"}, URL.ErrValueNotMap, "s[sub]"},
+			{[]any{"rows", "name"}, URL.ErrValueNotMap, "rows[name]"},
+			{[]any{"address", 0}, URL.ErrValueNotSlice, "address[0]"},
+			{[]any{"rows", -1}, URL.ErrInvalidIndex, "rows[-1]"},
+			{[]any{}, URL.ErrEmptyPath, ""},
+		}
+		for _, test := range tests {
+			err := mapV.Set("x", test.path...)
+			var perr *URL.PathError
+			if !errors.As(err, &perr) || !errors.Is(err, test.err) || perr.Path != test.key {
+				t.Errorf("%v: expected %v at %s, found %v", test.path, test.err, test.key, err)
+			}
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := mapV.Delete("rows", 0); err != nil {
+			t.Fatal(err)
+		}
+		v, err := mapV.GetValue("rows", 1, "name")
+		if err != nil || v.Key() != "rows[1][name]" {
+			t.Fatalf("rows[1][name]: unexpected %v (%v)", v, err)
+		}
+		if s, _ := v.String(); s != "c" {
+			t.Errorf("rows[1][name]: expected c found %q", s)
+		}
+		if err := mapV.Delete("address"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mapV.GetValue("address"); err == nil {
+			t.Error("address: expected key to be deleted")
+		}
+		if err := mapV.Delete("missing"); !errors.Is(err, URL.ErrKeyNotFound) {
+			t.Errorf("expected ErrKeyNotFound, found %v", err)
+		}
+		if err := mapV.Delete("rows", 5); !errors.Is(err, URL.ErrKeyNotFound) {
+			t.Errorf("expected ErrKeyNotFound, found %v", err)
+		}
+	})
+
+	t.Run("append and insert", func(t *testing.T) {
+		if err := mapV.Append([]any{"list"}, "b", "d"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Insert([]any{"list"}, 0, "a"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Insert([]any{"list"}, 2, "c"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Append([]any{"list"}, "e"); err != nil {
+			t.Fatal(err)
+		}
+		if s := strings.Join(mapV.GetStrings("list"), ","); s != "a,b,c,d,e" {
+			t.Errorf("list: expected a,b,c,d,e found %q", s)
+		}
+		for i := 0; i < 5; i++ {
+			if v, _ := mapV.GetValue("list", i); v.Key() != "list["+string(rune('0'+i))+"]" {
+				t.Errorf("list[%d]: unexpected key %s", i, v.Key())
+			}
+		}
+		if err := mapV.Insert([]any{"list"}, 7, "x"); !errors.Is(err, URL.ErrInvalidIndex) {
+			t.Errorf("expected ErrInvalidIndex, found %v", err)
+		}
+		if err := mapV.Append([]any{"s"}, "x"); !errors.Is(err, URL.ErrValueNotSlice) {
+			t.Errorf("expected ErrValueNotSlice, found %v", err)
+		}
+	})
+}
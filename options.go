@@ -0,0 +1,133 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseOptions defines the limits enforced while parsing and how ambiguous keys are handled.
+//
+// Limits protect the parser from payloads crafted to exhaust memory, for example
+// "a[100000000]=x" would otherwise allocate a hundred million ValueNil elements.
+// A zero or negative limit is not enforced, the zero value ParseOptions applies no limits
+// and rejects negative and non-canonical slice indices.
+type ParseOptions struct {
+	// MaxDepth is the maximum number of nested keys, "a[b][0]" has a depth of 2.
+	MaxDepth int
+	// MaxSliceIndex is the highest slice index a key can address, "key[]" addresses len(key).
+	MaxSliceIndex int
+	// MaxKeys is the maximum number of keys parsed.
+	MaxKeys int
+	// MaxNodes is the maximum number of Values created, ValueNil gaps included.
+	MaxNodes int
+	// MaxValueBytes is the maximum length of a single value.
+	MaxValueBytes int
+	// NegativeIndex defines how negative indices, ie "a[-1]", are handled.
+	NegativeIndex NegativeIndexPolicy
+	// NonCanonicalIndex defines how indices such as "a[01]", "a[+1]" or "a[-0]" are handled.
+	NonCanonicalIndex NonCanonicalIndexPolicy
+	// Duplicates defines how keys submitted more than once, ie "a=1&a=2", are handled.
+	Duplicates DuplicatePolicy
+	// MixedKeys converts a ValueSlice receiving a map key, or a ValueMap receiving a slice index, into a ValueArray
+	// holding both, "input[0]=a&input[key]=b" keeps both values regardless of their order.
+	MixedKeys bool
+	// Dialect parses keys and values following the conventions of a web framework, see Dialect.
+	// A nil Dialect parses "a[b][0]" keys as described in the package documentation.
+	Dialect Dialect
+	// Strict reports every ignored key, the parser returns a *ParseErrors along with the Map.
+	Strict bool
+}
+
+// NegativeIndexPolicy defines how negative slice indices are handled.
+type NegativeIndexPolicy int
+
+const (
+	// NegativeIndexUnset is the zero value, it uses the rule of ParseOptions.Dialect, NegativeIndexReject without a Dialect.
+	NegativeIndexUnset NegativeIndexPolicy = iota
+	// NegativeIndexReject ignores keys with a negative index.
+	NegativeIndexReject
+	// NegativeIndexAsKey handles negative indices as map keys, "a[-1]" is the key "-1" of the map "a".
+	NegativeIndexAsKey
+	// NegativeIndexFromEnd counts negative indices from the end of the slice, "a[-1]" is the last element of "a".
+	// Keys addressing an element before the start of the slice are ignored.
+	// In a ValueArray the int keys are counted in insertion order, "a[-1]" is the last int key inserted.
+	NegativeIndexFromEnd
+)
+
+// NonCanonicalIndexPolicy defines how integer indices not written in their canonical form are handled.
+//
+// An index is canonical when it is written as strconv.Itoa() would, "1" is canonical while "01", "+1" and "-0" are not.
+type NonCanonicalIndexPolicy int
+
+const (
+	// NonCanonicalIndexUnset is the zero value, it uses the rule of ParseOptions.Dialect, NonCanonicalIndexReject without a Dialect.
+	NonCanonicalIndexUnset NonCanonicalIndexPolicy = iota
+	// NonCanonicalIndexReject ignores keys with a non-canonical index.
+	NonCanonicalIndexReject
+	// NonCanonicalIndexAsKey handles non-canonical indices as map keys, "a[01]" is the key "01" of the map "a".
+	NonCanonicalIndexAsKey
+	// NonCanonicalIndexNormalize converts the index in its canonical form, "a[01]" addresses the same element as "a[1]".
+	NonCanonicalIndexNormalize
+)
+
+// DuplicatePolicy defines how values submitted more than once for the same key are handled.
+//
+// A key is duplicated when it is submitted more than once, ie "a=1&a=2" or "a[0]=x&a[0]=y",
+// or when url.Values holds more than one value for it. "key[]" appends its values and is never a duplicate.
+type DuplicatePolicy int
+
+const (
+	// DuplicateUnset is the zero value, it uses the rule of ParseOptions.Dialect, DuplicateDefault without a Dialect.
+	DuplicateUnset DuplicatePolicy = iota
+	// DuplicateDefault keeps the behaviour of previous versions, ParseValues stores the first value
+	// submitted for a key while ParseQuery and ParseReader store the last one.
+	DuplicateDefault
+	// DuplicateFirst stores the first value submitted, later values are ignored.
+	DuplicateFirst
+	// DuplicateLast stores the last value submitted.
+	DuplicateLast
+	// DuplicatePromote stores every value, a key submitted more than once becomes a ValueSlice,
+	// "tag=a&tag=b" is parsed as "{ tag : [ 0 => a, 1 => b ] }".
+	DuplicatePromote
+	// DuplicateError stores the first value submitted and reports the duplicates with ErrDuplicateKey.
+	// Unlike other parsing errors the first duplicate is returned even when ParseOptions.Strict is not set.
+	DuplicateError
+)
+
+var ErrDuplicateKey = errors.New("duplicate key")
+var ErrLimitExceeded = errors.New("limit exceeded")
+var ErrInvalidIndex = errors.New("invalid slice index")
+
+// LimitError is returned when the input exceeds one of the ParseOptions limits.
+//
+// errors.Is(err, ErrLimitExceeded) reports true for any LimitError.
+type LimitError struct {
+	// Limit is the name of the exceeded ParseOptions field, ie "MaxDepth"
+	Limit string
+	// Max is the configured limit
+	Max int
+	// Key is the raw key being parsed when the limit was exceeded
+	Key string
+	// Err is the error reported by the FileStore when Limit is "Store", ie ErrStoreFull
+	Err error
+}
+
+func (e *LimitError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s %s: %v", e.Key, e.Limit, ErrLimitExceeded, e.Err)
+	}
+	return fmt.Sprintf("%s: %s %s (%d)", e.Key, e.Limit, ErrLimitExceeded, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// returns true if limit is enforced and n is above it.
+func exceeds(n, limit int) bool {
+	return limit > 0 && n > limit
+}
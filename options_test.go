@@ -0,0 +1,177 @@
+package url_test
+
+import (
+	"errors"
+	"maps"
+	"net/url"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestParseOptionsLimits(t *testing.T) {
+	var tests = []struct {
+		name  string
+		raw   string
+		opts  URL.ParseOptions
+		limit string
+	}{
+		{"slice index", "a[100000000]=x", URL.ParseOptions{MaxSliceIndex: 100}, "MaxSliceIndex"},
+		{"slice append", "a[]=x&a[]=y&a[]=z", URL.ParseOptions{MaxSliceIndex: 1}, "MaxSliceIndex"},
+		{"depth", "a[b][c][d]=x", URL.ParseOptions{MaxDepth: 2}, "MaxDepth"},
+		{"keys", "a=1&b=2&c=3", URL.ParseOptions{MaxKeys: 2}, "MaxKeys"},
+		{"nodes", "a[5]=x", URL.ParseOptions{MaxNodes: 5}, "MaxNodes"},
+		{"value bytes", "a=0123456789", URL.ParseOptions{MaxValueBytes: 8}, "MaxValueBytes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapV, err := URL.ParseQueryWithOptions(test.raw, test.opts)
+			if mapV != nil {
+				t.Error("expected a nil Map")
+			}
+			if !errors.Is(err, URL.ErrLimitExceeded) {
+				t.Fatalf("expected ErrLimitExceeded, found %v", err)
+			}
+			var limitErr *URL.LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected *LimitError, found %T", err)
+			}
+			if limitErr.Limit != test.limit {
+				t.Errorf("expected %s found %s", test.limit, limitErr.Limit)
+			}
+		})
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		src := url.Values{"a[2]": {"x"}, "b[c][d]": {"y"}}
+		opts := URL.ParseOptions{MaxDepth: 2, MaxSliceIndex: 2, MaxKeys: 2, MaxNodes: 7, MaxValueBytes: 1}
+		mapV, err := URL.ParseValuesWithOptions(src, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("b", "c", "d"); s != "y" {
+			t.Errorf("b[c][d]: expected y found %q", s)
+		}
+	})
+
+	t.Run("url.Values append", func(t *testing.T) {
+		src := url.Values{"a[]": {"x", "y", "z"}}
+		if _, err := URL.ParseValuesWithOptions(src, URL.ParseOptions{MaxNodes: 3}); !errors.Is(err, URL.ErrLimitExceeded) {
+			t.Errorf("expected ErrLimitExceeded, found %v", err)
+		}
+	})
+}
+
+func TestParseOptionsIndex(t *testing.T) {
+	var tests = []struct {
+		name     string
+		raw      string
+		opts     URL.ParseOptions
+		keys     []any
+		expected string
+		dropped  []any
+	}{
+		{"negative rejected", "a[]=x&a[-1]=y&a[-2]=z", URL.ParseOptions{}, []any{"a", 0}, "x", []any{"a", 1}},
+		{"negative as key", "a[-1]=y", URL.ParseOptions{NegativeIndex: URL.NegativeIndexAsKey}, []any{"a", "-1"}, "y", nil},
+		{"negative from end", "a[]=x&a[]=y&a[-1]=z", URL.ParseOptions{NegativeIndex: URL.NegativeIndexFromEnd}, []any{"a", 1}, "z", []any{"a", 2}},
+		{"negative before start", "a[]=x&a[-2]=z", URL.ParseOptions{NegativeIndex: URL.NegativeIndexFromEnd}, []any{"a", 0}, "x", []any{"a", 1}},
+		{"negative on missing slice", "b=x&a[-1]=z", URL.ParseOptions{NegativeIndex: URL.NegativeIndexFromEnd}, []any{"b"}, "x", []any{"a"}},
+		{"negative on missing nested slice", "b=x&a[c][-1]=z", URL.ParseOptions{NegativeIndex: URL.NegativeIndexFromEnd}, []any{"b"}, "x", []any{"a"}},
+		{"non-canonical rejected", "a[1]=x&a[01]=y&a[%2B1]=z", URL.ParseOptions{}, []any{"a", 1}, "x", []any{"a", 2}},
+		{"non-canonical as key", "a[01]=y", URL.ParseOptions{NonCanonicalIndex: URL.NonCanonicalIndexAsKey}, []any{"a", "01"}, "y", []any{"a", 1}},
+		{"non-canonical normalized", "a[007]=y", URL.ParseOptions{NonCanonicalIndex: URL.NonCanonicalIndexNormalize}, []any{"a", 7}, "y", []any{"a", 8}},
+		{"out of range", "a[99999999999999999999]=y&b=x", URL.ParseOptions{}, []any{"b"}, "x", []any{"a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapV, err := URL.ParseQueryWithOptions(test.raw, test.opts)
+			if mapV == nil {
+				t.Fatal(err)
+			}
+			if s := mapV.GetString(test.keys...); s != test.expected {
+				t.Errorf("%v: expected %q found %q", test.keys, test.expected, s)
+			}
+			if test.dropped == nil {
+				return
+			}
+			if _, err := mapV.GetValue(test.dropped...); err == nil {
+				t.Errorf("%v: expected value not to be set", test.dropped)
+			}
+		})
+	}
+}
+
+func TestParseOptionsDuplicates(t *testing.T) {
+	var tests = []struct {
+		name   string
+		policy URL.DuplicatePolicy
+		query  map[string]string
+		values map[string]string
+	}{
+		{"default", URL.DuplicateDefault, map[string]string{"a": "2", "b[0]": "y", "c": "v"}, map[string]string{"a": "1", "b[0]": "x", "c": "v"}},
+		{"first", URL.DuplicateFirst, map[string]string{"a": "1", "b[0]": "x", "c": "v"}, map[string]string{"a": "1", "b[0]": "x", "c": "v"}},
+		{"last", URL.DuplicateLast, map[string]string{"a": "2", "b[0]": "y", "c": "v"}, map[string]string{"a": "2", "b[0]": "y", "c": "v"}},
+		{"promote", URL.DuplicatePromote,
+			map[string]string{"a[0]": "1", "a[1]": "2", "b[0][0]": "x", "b[0][1]": "y", "c": "v"},
+			map[string]string{"a[0]": "1", "a[1]": "2", "b[0][0]": "x", "b[0][1]": "y", "c": "v"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := URL.ParseOptions{Duplicates: test.policy}
+			mapV, err := URL.ParseQueryWithOptions("a=1&a=2&b[0]=x&b[0]=y&c=v", opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kv := mapV.KeyValue(); !maps.Equal(kv, test.query) {
+				t.Errorf("ParseQuery: expected %v found %v", test.query, kv)
+			}
+			mapV, err = URL.ParseValuesWithOptions(url.Values{"a": {"1", "2"}, "b[0]": {"x", "y"}, "c": {"v"}}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kv := mapV.KeyValue(); !maps.Equal(kv, test.values) {
+				t.Errorf("ParseValues: expected %v found %v", test.values, kv)
+			}
+		})
+	}
+
+	t.Run("promote appends", func(t *testing.T) {
+		mapV, err := URL.ParseQueryWithOptions("tag=a&tag=b&tag=c&list[]=x&list=y", URL.ParseOptions{Duplicates: URL.DuplicatePromote})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetStrings("tag"); len(s) != 3 || s[2] != "c" {
+			t.Errorf("tag: expected [a b c] found %v", s)
+		}
+		if s := mapV.GetStrings("list"); len(s) != 2 || s[1] != "y" {
+			t.Errorf("list: expected [x y] found %v", s)
+		}
+		if _, err := URL.ParseQueryWithOptions("tag=a&tag=b&tag=c", URL.ParseOptions{Duplicates: URL.DuplicatePromote, MaxNodes: 3}); !errors.Is(err, URL.ErrLimitExceeded) {
+			t.Errorf("expected ErrLimitExceeded, found %v", err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		opts := URL.ParseOptions{Duplicates: URL.DuplicateError}
+		mapV, err := URL.ParseQueryWithOptions("a=1&b=x&a=2&b=y", opts)
+		var perr *URL.ParseError
+		if !errors.Is(err, URL.ErrDuplicateKey) || !errors.As(err, &perr) || perr.Key != "a" || perr.Reason != URL.ReasonDuplicateKey {
+			t.Errorf("expected ErrDuplicateKey for a, found %v", err)
+		}
+		if mapV == nil || mapV.GetString("a") != "1" {
+			t.Error("expected the first value to be kept")
+		}
+
+		_, err = URL.ParseValuesWithOptions(url.Values{"a": {"1", "2"}}, opts)
+		if !errors.Is(err, URL.ErrDuplicateKey) {
+			t.Errorf("ParseValues: expected ErrDuplicateKey, found %v", err)
+		}
+
+		opts.Strict = true
+		_, err = URL.ParseQueryWithOptions("a=1&b=x&a=2&b=y", opts)
+		var errs *URL.ParseErrors
+		if !errors.As(err, &errs) || len(errs.Errors) != 2 {
+			t.Errorf("Strict: expected 2 errors found %v", err)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package url
+
+// orderedMap is the map backing ValueMap, it remembers the order keys were inserted in.
+type orderedMap struct {
+	// keys in insertion order
+	keys     []string
+	elements map[string]Value
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{elements: make(map[string]Value)}
+}
+
+func (m *orderedMap) get(k string) (Value, bool) {
+	v, ok := m.elements[k]
+	return v, ok
+}
+
+// stores v at k, new keys are added after the existing ones.
+func (m *orderedMap) set(k string, v Value) {
+	if _, ok := m.elements[k]; !ok {
+		m.keys = append(m.keys, k)
+	}
+	m.elements[k] = v
+}
+
+func (m *orderedMap) delete(k string) {
+	if _, ok := m.elements[k]; !ok {
+		return
+	}
+	delete(m.elements, k)
+	for i, key := range m.keys {
+		if key == k {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
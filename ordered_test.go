@@ -0,0 +1,82 @@
+package url_test
+
+import (
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestValueMapOrder(t *testing.T) {
+	raw := "z=1&b%5By%5D=2&b%5Bx%5D=3&a%5B0%5D=4&m=5"
+	mapV, err := URL.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := mapV.GetValue()
+	if keys := root.StringKeys(); !reflect.DeepEqual(keys, []string{"z", "b", "a", "m"}) {
+		t.Errorf("StringKeys: unexpected %v", keys)
+	}
+	if s := mapV.Encode(URL.EncodeOptions{Order: URL.OrderInsertion}); s != raw {
+		t.Errorf("Encode: expected %s found %s", raw, s)
+	}
+	b, _ := mapV.MarshalJSON()
+	if string(b) != `{"z":"1","b":{"y":"2","x":"3"},"a":["4"],"m":"5"}` {
+		t.Errorf("MarshalJSON: unexpected %s", b)
+	}
+
+	t.Run("mutate", func(t *testing.T) {
+		if err := mapV.Set("6", "z"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Delete("b"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mapV.Set("7", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if keys := root.StringKeys(); !reflect.DeepEqual(keys, []string{"z", "a", "m", "b"}) {
+			t.Errorf("StringKeys: unexpected %v", keys)
+		}
+		if root.Len() != 4 {
+			t.Errorf("Len: expected 4 found %d", root.Len())
+		}
+	})
+
+	t.Run("map copy", func(t *testing.T) {
+		m, _ := root.Map()
+		m["new"] = m["z"]
+		delete(m, "a")
+		if keys := root.StringKeys(); !reflect.DeepEqual(keys, []string{"z", "a", "m", "b"}) {
+			t.Errorf("StringKeys: unexpected %v", keys)
+		}
+		if n := len(root.ToSlice()); root.Len() != 4 || n != 4 {
+			t.Errorf("Len: expected 4 found %d, %d elements", root.Len(), n)
+		}
+		if _, err := mapV.GetValue("a"); err != nil {
+			t.Errorf("expected a to be kept, found %v", err)
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		m := URL.NewMap()
+		if err := m.Set(mapV, "copy"); err != nil {
+			t.Fatal(err)
+		}
+		c, _ := m.GetValue("copy")
+		if keys := c.StringKeys(); !reflect.DeepEqual(keys, root.StringKeys()) {
+			t.Errorf("StringKeys: unexpected %v", keys)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		m, err := URL.FromJSON([]byte(`{"b":"1","a":"2","b":"3"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := m.GetValue()
+		if keys := v.StringKeys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+			t.Errorf("StringKeys: unexpected %v", keys)
+		}
+	})
+}
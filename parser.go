@@ -0,0 +1,675 @@
+package url
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// field is a key and the values submitted for it.
+type field struct {
+	key    string
+	values []string
+	// files submitted for key, multipart forms only
+	files []*File
+}
+
+// splits an application/x-www-form-urlencoded payload into fields, one per key/value pair,
+// in the order they appear in raw.
+//
+// Like url.ParseQuery, pairs that cannot be decoded are skipped, an error is reported for each of them.
+func tokenizeQuery(raw string) (fields []field, errs []*ParseError) {
+	fields = make([]field, 0, strings.Count(raw, "&")+1)
+	for raw != "" {
+		var pair string
+		pair, raw, _ = strings.Cut(raw, "&")
+		if pair == "" {
+			continue
+		}
+		rawKey, value, _ := strings.Cut(pair, "=")
+		if strings.Contains(pair, ";") {
+			errs = append(errs, &ParseError{Key: rawKey, Segment: rawKey, Reason: ReasonInvalidEncoding, Err: errors.New("invalid semicolon separator in query")})
+			continue
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			// the key cannot be decoded, it is reported as submitted
+			errs = append(errs, &ParseError{Key: rawKey, Segment: rawKey, Reason: ReasonInvalidEncoding, Err: err})
+			continue
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			errs = append(errs, &ParseError{Key: key, Segment: key, Reason: ReasonInvalidEncoding, Err: err})
+			continue
+		}
+		fields = append(fields, field{key: key, values: []string{value}})
+	}
+	return fields, errs
+}
+
+// parser builds a Map out of fields, enforcing the ParseOptions limits.
+type parser struct {
+	opts ParseOptions
+	// rules of opts.Dialect, if any
+	rules DialectRules
+	nodes int
+	// errors found while parsing
+	errs []*ParseError
+}
+
+func newParser(opts ParseOptions) *parser {
+	p := &parser{opts: opts}
+	if opts.Dialect != nil {
+		p.rules = opts.Dialect.Rules()
+		if p.opts.Duplicates == DuplicateUnset {
+			p.opts.Duplicates = p.rules.Duplicates
+		}
+		if p.opts.NegativeIndex == NegativeIndexUnset {
+			p.opts.NegativeIndex = p.rules.NegativeIndex
+		}
+		if p.opts.NonCanonicalIndex == NonCanonicalIndexUnset {
+			p.opts.NonCanonicalIndex = p.rules.NonCanonicalIndex
+		}
+	}
+	return p
+}
+
+// splits key into root and nested keys, using the dialect if any.
+func (p *parser) splitKey(key string) (root string, nestedKeys []string, err error) {
+	if p.opts.Dialect != nil {
+		return p.opts.Dialect.SplitKey(key)
+	}
+	return getParseKey(key)
+}
+
+// returns the values of f stored into leaves, either strings split using the dialect if any or *File.
+func (p *parser) leafValues(f field) []any {
+	out := make([]any, 0, len(f.values)+len(f.files))
+	for _, file := range f.files {
+		out = append(out, file)
+	}
+	for _, s := range f.values {
+		if p.opts.Dialect == nil {
+			out = append(out, s)
+			continue
+		}
+		for _, split := range p.opts.Dialect.SplitValue(s) {
+			out = append(out, split)
+		}
+	}
+	return out
+}
+
+// returns the type of the leaf holding x, a string or *File.
+func leafType(x any) ValueType {
+	if _, ok := x.(*File); ok {
+		return ValueFile
+	}
+	return ValueString
+}
+
+// stores x, a string or *File, into the leaf val.
+func storeLeaf(val Value, x any) {
+	val.(*item).to(leafType(x)).setValue(x)
+}
+
+// parse processes fields in order and returns the resulting Map.
+//
+// Keys that cannot be parsed are ignored, if ParseOptions.Strict is set the returned error
+// is a *ParseErrors reporting each of them. Otherwise only the first decoding error is returned.
+// Limits stop the parser, the returned Map is nil.
+func (p *parser) parse(fields []field) (m Map, err error) {
+	out := newNilValue("").to(ValueMap)
+	for i, f := range fields {
+		if err := p.next(out, i, f); err != nil {
+			return nil, err
+		}
+	}
+	return p.result(out)
+}
+
+// next parses f, the field at index i, into out. The returned error is not nil when a limit stops the parser.
+func (p *parser) next(out Value, i int, f field) error {
+	var perr *ParseError
+	if exceeds(i+1, p.opts.MaxKeys) {
+		perr = newParseError(f.key, f.key, nil, &LimitError{Limit: "MaxKeys", Max: p.opts.MaxKeys, Key: f.key})
+	} else if perr = p.parseField(out, f); perr == nil {
+		return nil
+	}
+	p.errs = append(p.errs, perr)
+	if perr.Reason == ReasonLimitExceeded {
+		if p.opts.Strict {
+			return &ParseErrors{Errors: p.errs}
+		}
+		return perr.Err
+	}
+	return nil
+}
+
+// result returns out once every field is parsed, along with the errors collected as described by parse.
+func (p *parser) result(out Value) (m Map, err error) {
+	if p.rules.CompactSlices {
+		compact(out.(*item))
+	}
+	if p.opts.Strict {
+		if len(p.errs) > 0 {
+			return out, &ParseErrors{Errors: p.errs}
+		}
+		return out, nil
+	}
+	for _, perr := range p.errs {
+		if perr.Reason == ReasonInvalidEncoding {
+			return out, perr.Err
+		}
+		if perr.Reason == ReasonDuplicateKey {
+			return out, perr
+		}
+	}
+	return out, nil
+}
+
+// parseField descends out following the key of f and stores its value.
+func (p *parser) parseField(out Value, f field) *ParseError {
+	var value = p.leafValues(f)
+	if len(value) == 0 {
+		return nil
+	}
+	root, nestedKeys, err := p.splitKey(f.key)
+	if err != nil {
+		// missing [ or ]
+		// malformed input, ignoring value
+		return newParseError(f.key, f.key, nil, err)
+	}
+	if exceeds(len(nestedKeys), p.opts.MaxDepth) {
+		return newParseError(f.key, nestedKeys[p.opts.MaxDepth], nil, &LimitError{Limit: "MaxDepth", Max: p.opts.MaxDepth, Key: f.key})
+	}
+	for _, s := range f.values {
+		if exceeds(len(s), p.opts.MaxValueBytes) {
+			return newParseError(f.key, f.key, nil, &LimitError{Limit: "MaxValueBytes", Max: p.opts.MaxValueBytes, Key: f.key})
+		}
+	}
+
+	segments, perr := p.segments(f.key, nestedKeys)
+	if perr != nil {
+		// keyPart is an integer the options do not allow
+		// malformed input, ignoring value
+		return perr
+	}
+
+	if p.opts.NegativeIndex == NegativeIndexFromEnd {
+		if perr := p.checkNegative(f.key, out, root, segments); perr != nil {
+			// checked before descending, a rejected key does not create its containers
+			return perr
+		}
+	}
+
+	var appendSlice = false
+	currentValue, err := p.mapFor(f.key, out, root)
+	if err != nil {
+		return newParseError(f.key, root, out, err)
+	}
+	previousValue := currentValue
+	for i, seg := range segments {
+		appendSlice = false
+		previousValue = currentValue
+		if p.useArray(currentValue, seg) {
+			appendSlice = seg.kind == segmentAppend
+			if currentValue, err = p.arrayAt(f.key, currentValue, seg); err != nil {
+				return newParseError(f.key, seg.key, previousValue, err)
+			}
+			continue
+		}
+		switch seg.kind {
+		case segmentAppend:
+			appendSlice = true
+			if !currentValue.cast(ValueSlice) {
+				// "[]" expects either nil or slice.
+				// malformed input, ignoring value
+				return newParseError(f.key, seg.key, currentValue, ErrValueNotSlice)
+			}
+			if last := lastElement(currentValue); p.rules.MergeAppend && last != nil && last.Is(ValueMap) && !hasSegments(last, segments[i+1:]) {
+				// the last element does not hold the key yet, the value is merged into it
+				currentValue = last
+				continue
+			}
+			// currentValue is a slice, but if the code reaches here, the next value will be a map.
+			// creates a slice element to host the new map element.
+			if currentValue, err = p.sliceAt(f.key, currentValue, -1); err != nil {
+				// cannot set slice value for keyPart
+				// malformed input, ignoring value
+				return newParseError(f.key, seg.key, previousValue, err)
+			}
+		case segmentIndex:
+			if !currentValue.cast(ValueSlice) {
+				// keyPart is an integer, so it is expected to be either nil or slice.
+				// malformed input, ignoring value
+				return newParseError(f.key, seg.key, currentValue, ErrValueNotSlice)
+			}
+			sIndex := seg.index
+			if sIndex < 0 {
+				// NegativeIndexFromEnd
+				if sIndex += currentValue.Len(); sIndex < 0 {
+					return newParseError(f.key, seg.key, currentValue, ErrInvalidIndex)
+				}
+			}
+			if currentValue, err = p.sliceAt(f.key, currentValue, sIndex); err != nil {
+				// cannot set slice value for keyPart
+				// malformed input, ignoring value
+				return newParseError(f.key, seg.key, previousValue, err)
+			}
+		default:
+			if currentValue, err = p.mapFor(f.key, currentValue, seg.key); err != nil {
+				// cannot set map key value for keyPart
+				// malformed input, ignoring value
+				return newParseError(f.key, seg.key, previousValue, err)
+			}
+		}
+	}
+
+	if appendSlice && previousValue.Is(ValueArray) && len(value) > 1 {
+		storeLeaf(currentValue, value[0])
+		for _, s := range value[1:] {
+			if currentValue, err = p.arrayAt(f.key, previousValue, segment{kind: segmentAppend}); err != nil {
+				return newParseError(f.key, lastKeyPart(root, nestedKeys), previousValue, err)
+			}
+			storeLeaf(currentValue, s)
+		}
+		return nil
+	}
+
+	if appendSlice && previousValue.Is(ValueSlice) && len(value) > 1 {
+		storeLeaf(currentValue, value[0])
+		// value is a slice, creates elements
+		for _, s := range value[1:] {
+			if currentValue, err = p.sliceAt(f.key, previousValue, -1); err != nil {
+				return newParseError(f.key, lastKeyPart(root, nestedKeys), previousValue, err)
+			}
+			storeLeaf(currentValue, s)
+		}
+		return nil
+	}
+
+	return p.setLeaf(f.key, lastKeyPart(root, nestedKeys), currentValue, value)
+}
+
+// stores values into the leaf val, applying the ParseOptions duplicate policy.
+func (p *parser) setLeaf(key, keyPart string, val Value, values []any) *ParseError {
+	isSet := val.Is(ValueString) || val.Is(ValueFile)
+	switch p.opts.Duplicates {
+	case DuplicateFirst:
+		if isSet {
+			return nil
+		}
+		values = values[:1]
+	case DuplicateLast:
+		values = values[len(values)-1:]
+	case DuplicatePromote:
+		return p.promote(key, keyPart, val, values)
+	case DuplicateError:
+		if isSet {
+			return newParseError(key, keyPart, val, ErrDuplicateKey)
+		}
+		if len(values) > 1 && val.cast(leafType(values[0])) {
+			val.setValue(values[0])
+			return newParseError(key, keyPart, val, ErrDuplicateKey)
+		}
+		values = values[:1]
+	}
+
+	if !val.cast(leafType(values[0])) {
+		// cannot cast the current value
+		// malformed input, ignoring value
+		return newParseError(key, keyPart, val, ErrValueNotString)
+	}
+
+	val.setValue(values[0])
+	return nil
+}
+
+// stores values into the leaf val, a leaf holding more than one value becomes a ValueSlice.
+func (p *parser) promote(key, keyPart string, val Value, values []any) *ParseError {
+	if val.IsNil() && len(values) == 1 {
+		storeLeaf(val, values[0])
+		return nil
+	}
+	if val.Is(ValueString) || val.Is(ValueFile) {
+		// the leaf becomes the first element
+		leaf := val.(*item)
+		values = append([]any{leaf.value}, values...)
+		leaf.valueType, leaf.value = ValueNil, ""
+	}
+	if !val.cast(ValueSlice) {
+		return newParseError(key, keyPart, val, ErrValueNotString)
+	}
+	for _, s := range values {
+		element, err := p.sliceAt(key, val, -1)
+		if err != nil {
+			return newParseError(key, keyPart, val, err)
+		}
+		storeLeaf(element, s)
+	}
+	return nil
+}
+
+// returns the last part of the key, either the last nested key or root.
+func lastKeyPart(root string, nestedKeys []string) string {
+	if len(nestedKeys) == 0 {
+		return root
+	}
+	return nestedKeys[len(nestedKeys)-1]
+}
+
+type segmentKind int
+
+const (
+	// map key
+	segmentKey segmentKind = iota
+	// slice index
+	segmentIndex
+	// "[]", appends to a slice
+	segmentAppend
+)
+
+// segment is a nested key classified by the parser.
+type segment struct {
+	key   string
+	index int
+	kind  segmentKind
+}
+
+// classifies the nested keys before the parser descends into the Map.
+func (p *parser) segments(key string, nestedKeys []string) (segments []segment, perr *ParseError) {
+	segments = make([]segment, len(nestedKeys))
+	for i, keyPart := range nestedKeys {
+		segments[i].key = keyPart
+		if keyPart == "" {
+			segments[i].kind = segmentAppend
+			continue
+		}
+		index, isIndex, err := p.index(keyPart)
+		if err != nil {
+			return nil, newParseError(key, keyPart, nil, err)
+		}
+		if isIndex {
+			segments[i].kind = segmentIndex
+			segments[i].index = index
+		}
+	}
+	return segments, nil
+}
+
+// index reports whether keyPart addresses a slice element and its index, applying the options index policies.
+// A negative index is returned only when NegativeIndexFromEnd is set.
+func (p *parser) index(keyPart string) (sIndex int, isIndex bool, err error) {
+	if p.rules.NumericKeys {
+		return 0, false, nil
+	}
+	sIndex, err = strconv.Atoi(keyPart)
+	if err == nil && p.rules.ArrayLimit > 0 && sIndex > p.rules.ArrayLimit {
+		return 0, false, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, false, ErrInvalidIndex
+	} else if err != nil {
+		return 0, false, nil
+	}
+	if strconv.Itoa(sIndex) != keyPart {
+		switch p.opts.NonCanonicalIndex {
+		case NonCanonicalIndexAsKey:
+			return 0, false, nil
+		case NonCanonicalIndexNormalize:
+		default:
+			return 0, false, ErrInvalidIndex
+		}
+	}
+	if sIndex < 0 {
+		switch p.opts.NegativeIndex {
+		case NegativeIndexAsKey:
+			return 0, false, nil
+		case NegativeIndexFromEnd:
+		default:
+			return 0, false, ErrInvalidIndex
+		}
+	}
+	return sIndex, true, nil
+}
+
+// reports whether the element addressed by seg is stored in a ValueArray, converting val if needed.
+func (p *parser) useArray(val Value, seg segment) bool {
+	switch {
+	case val.Is(ValueArray):
+		return true
+	case val.IsNil():
+		if p.rules.Arrays {
+			val.to(ValueArray)
+			return true
+		}
+	case !p.opts.MixedKeys:
+	case val.Is(ValueSlice) && seg.kind == segmentKey, val.Is(ValueMap) && seg.kind != segmentKey:
+		val.(*item).toArray()
+		return true
+	}
+	return false
+}
+
+// returns the element of the ValueArray val addressed by seg, creating it if missing.
+func (p *parser) arrayAt(key string, val Value, seg segment) (Value, error) {
+	var k any
+	switch seg.kind {
+	case segmentKey:
+		k = seg.key
+	case segmentIndex:
+		k = seg.index
+		if seg.index < 0 {
+			// NegativeIndexFromEnd, counts the int keys from the last one inserted
+			keys := val.IntKeys()
+			i := len(keys) + seg.index
+			if i < 0 {
+				return nil, ErrInvalidIndex
+			}
+			k = keys[i]
+		}
+	}
+	a := val.(*item).value.(*array)
+	if k != nil {
+		if v, ok := a.get(k); ok {
+			return v, nil
+		}
+	}
+	if i, ok := k.(int); ok && exceeds(i, p.opts.MaxSliceIndex) || k == nil && exceeds(a.next, p.opts.MaxSliceIndex) {
+		return nil, &LimitError{Limit: "MaxSliceIndex", Max: p.opts.MaxSliceIndex, Key: key}
+	}
+	if err := p.grow(key, 1); err != nil {
+		return nil, err
+	}
+	return val.(*item).arrayFor(k)
+}
+
+// reports the first negative index of segments addressing no existing element, following the Values already
+// stored at root in out. A missing container holds no element and rejects any negative index.
+func (p *parser) checkNegative(key string, out Value, root string, segments []segment) *ParseError {
+	m, _ := mapElements(out)
+	val := m[root]
+	for i, seg := range segments {
+		if val == nil || val.IsNil() {
+			if seg.kind == segmentIndex && seg.index < 0 {
+				return newParseError(key, seg.key, val, ErrInvalidIndex)
+			}
+			val = nil
+			continue
+		}
+		switch {
+		case val.Is(ValueArray):
+			a := val.(*item).value.(*array)
+			var k any = seg.key
+			if seg.kind == segmentIndex {
+				k = seg.index
+				if keys := val.IntKeys(); seg.index < 0 {
+					if len(keys)+seg.index < 0 {
+						return newParseError(key, seg.key, val, ErrInvalidIndex)
+					}
+					k = keys[len(keys)+seg.index]
+				}
+			}
+			val, _ = a.get(k)
+		case val.Is(ValueSlice):
+			s, _ := val.Slice()
+			next := Value(nil)
+			switch seg.kind {
+			case segmentAppend:
+				if last := lastElement(val); p.rules.MergeAppend && last != nil && last.Is(ValueMap) && !hasSegments(last, segments[i+1:]) {
+					next = last
+				}
+			case segmentIndex:
+				k := seg.index
+				if k < 0 {
+					if k += len(s); k < 0 {
+						return newParseError(key, seg.key, val, ErrInvalidIndex)
+					}
+				}
+				if k < len(s) {
+					next = s[k]
+				}
+			}
+			val = next
+		case val.Is(ValueMap):
+			m, _ := mapElements(val)
+			val = nil
+			if seg.kind == segmentKey {
+				val = m[seg.key]
+			}
+		default:
+			// not a container, reported while descending
+			return nil
+		}
+	}
+	return nil
+}
+
+// returns the last element of the ValueSlice val, nil if val is empty.
+func lastElement(val Value) Value {
+	s, _ := val.Slice()
+	if len(s) == 0 {
+		return nil
+	}
+	return s[len(s)-1]
+}
+
+// reports whether val holds the map keys of segments, segments appending to a slice are never held.
+func hasSegments(val Value, segments []segment) bool {
+	for _, seg := range segments {
+		m, ok := mapElements(val)
+		if !ok || seg.kind == segmentAppend {
+			return false
+		}
+		if val, ok = m[seg.key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// removes the ValueNil gaps from the slices found in val.
+func compact(val *item) {
+	if s, ok := val.value.(*[]Value); ok {
+		out := (*s)[:0]
+		for _, v := range *s {
+			if !v.IsNil() {
+				out = append(out, v)
+			}
+		}
+		*s = out
+		rekey(val, val.key)
+	}
+	for _, e := range entries(val, OrderInsertion) {
+		compact(e.value.(*item))
+	}
+}
+
+// accounts for n new Values, fails if MaxNodes is exceeded.
+func (p *parser) grow(key string, n int) error {
+	if exceeds(p.nodes+n, p.opts.MaxNodes) {
+		return &LimitError{Limit: "MaxNodes", Max: p.opts.MaxNodes, Key: key}
+	}
+	p.nodes += n
+	return nil
+}
+
+// returns the value k of val, creating it if missing.
+func (p *parser) mapFor(key string, val Value, k string) (Value, error) {
+	if m, ok := mapElements(val); ok {
+		if v, ok := m[k]; ok {
+			return v, nil
+		}
+	} else if !val.IsNil() {
+		return nil, ErrValueNotMap
+	}
+	if err := p.grow(key, 1); err != nil {
+		return nil, err
+	}
+	return val.mapFor(k)
+}
+
+// returns the element at sliceIndex of val, growing the slice if needed.
+// sliceIndex -1 appends a new element.
+func (p *parser) sliceAt(key string, val Value, sliceIndex int) (Value, error) {
+	if !val.Is(ValueSlice) {
+		return nil, ErrValueNotSlice
+	}
+	length := val.Len()
+	if sliceIndex == -1 {
+		sliceIndex = length
+	}
+	if exceeds(sliceIndex, p.opts.MaxSliceIndex) {
+		return nil, &LimitError{Limit: "MaxSliceIndex", Max: p.opts.MaxSliceIndex, Key: key}
+	}
+	if sliceIndex >= length {
+		if err := p.grow(key, sliceIndex-length+1); err != nil {
+			return nil, err
+		}
+	}
+	return val.newNilValueAt(sliceIndex)
+}
+
+// ParseQuery parses an application/x-www-form-urlencoded string and returns a Map interface.
+//
+// Unlike ParseValues, keys are processed in the order they were submitted, so the payload
+//
+//	key[2]=C & key[]=A & key[1]=B
+//
+// results in
+//
+//	[ 0 => Nil, 1 => B, 2 => C, 3 => A ]
+//
+// and "key[]" values are appended in the order the browser/client sent them.
+// When the same key is submitted more than once, the last value is kept, see ParseOptions.Duplicates.
+//
+// As url.ParseQuery, pairs that cannot be unescaped are ignored and the first decoding error is returned.
+func ParseQuery(raw string) (m Map, err error) {
+	return ParseQueryWithOptions(raw, ParseOptions{})
+}
+
+// ParseQueryWithOptions behaves as ParseQuery, applying opts.
+// If a limit is exceeded parsing stops and a *LimitError is returned.
+// In strict mode decoding errors are reported in the returned *ParseErrors along with the keys that could not be parsed.
+func ParseQueryWithOptions(raw string, opts ParseOptions) (m Map, err error) {
+	fields, errs := tokenizeQuery(raw)
+	p := newParser(opts)
+	p.errs = errs
+	return p.parse(fields)
+}
+
+// ParseReader reads an application/x-www-form-urlencoded payload from r and parses it with ParseQuery.
+func ParseReader(r io.Reader) (m Map, err error) {
+	return ParseReaderWithOptions(r, ParseOptions{})
+}
+
+// ParseReaderWithOptions reads an application/x-www-form-urlencoded payload from r and parses it with ParseQueryWithOptions.
+func ParseReaderWithOptions(r io.Reader, opts ParseOptions) (m Map, err error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseQueryWithOptions(string(raw), opts)
+}
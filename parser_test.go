@@ -0,0 +1,84 @@
+package url_test
+
+import (
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Run("submission order", func(t *testing.T) {
+		mapV, err := URL.ParseQuery("key[2]=C&key[]=A&key[1]=B")
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := mapV.GetValue("key")
+		if v.Len() != 4 {
+			t.Fatalf("expected 4 elements, found %d", v.Len())
+		}
+		if v0, _ := mapV.GetValue("key", 0); !v0.IsNil() {
+			t.Errorf("expected key[0] to be ValueNil, found %s", v0.Type())
+		}
+		for i, expected := range []string{"", "B", "C", "A"} {
+			if s := mapV.GetString("key", i); s != expected {
+				t.Errorf("key[%d]: expected %q found %q", i, expected, s)
+			}
+		}
+	})
+
+	t.Run("appends", func(t *testing.T) {
+		mapV, err := URL.ParseQuery("input[][key1]=A&input[][key2]=B&tag[]=x&tag[]=y")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("input", 0, "key1"); s != "A" {
+			t.Errorf("input[0][key1]: expected A found %q", s)
+		}
+		if s := mapV.GetString("input", 1, "key2"); s != "B" {
+			t.Errorf("input[1][key2]: expected B found %q", s)
+		}
+		if s := strings.Join(mapV.GetStrings("tag"), ","); s != "x,y" {
+			t.Errorf("tag: expected x,y found %q", s)
+		}
+	})
+
+	t.Run("unescape", func(t *testing.T) {
+		mapV, err := URL.ParseQuery("a%5Bb%5D=hello+world&c=%26&&d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("a", "b"); s != "hello world" {
+			t.Errorf("a[b]: expected %q found %q", "hello world", s)
+		}
+		if s := mapV.GetString("c"); s != "&" {
+			t.Errorf("c: expected & found %q", s)
+		}
+		if v, err := mapV.GetValue("d"); err != nil || !v.Is(URL.ValueString) {
+			t.Errorf("d: expected an empty ValueString")
+		}
+	})
+
+	t.Run("invalid pairs", func(t *testing.T) {
+		mapV, err := URL.ParseQuery("a=%zz&b=1;c=2&d=ok")
+		if err == nil {
+			t.Error("expected a decoding error")
+		}
+		if s := mapV.GetString("d"); s != "ok" {
+			t.Errorf("d: expected ok found %q", s)
+		}
+		if _, err := mapV.GetValue("a"); err == nil {
+			t.Error("a: expected pair to be ignored")
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		mapV, err := URL.ParseReader(strings.NewReader("form[0]=A&form[2]=B"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := mapV.GetString("form", 2); s != "B" {
+			t.Errorf("form[2]: expected B found %q", s)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package url
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path addresses a Value in a Map, elements are either string map keys or int slice indices,
+// the same keys accepted by GetValue().
+//
+//	p := url.Path{"input4", "key2", 1}
+//	p.String()    // "input4[key2][1]"
+//	p.DotString() // "input4.key2.1"
+//	v, err := mapV.GetValue(p...)
+type Path []any
+
+// ParsePath parses a bracket path such as "input4[key2][1]", the format returned by Value.Key().
+//
+// The first element is always a map key, nested keys written as canonical non-negative integers
+// become slice indices, other nested keys are map keys. Empty brackets cannot be addressed,
+// "input[]" is an error, as is any text following the last bracket.
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	root, nestedKeys, err := getParseKey(s)
+	if err != nil {
+		return nil, err
+	}
+	p := make(Path, 0, len(nestedKeys)+1)
+	p = append(p, root)
+	for _, k := range nestedKeys {
+		if k == "" {
+			return nil, fmt.Errorf("%s: %w", s, ErrMalformedKey)
+		}
+		p = append(p, pathElement(k))
+	}
+	if p.String() != s {
+		return nil, fmt.Errorf("%s: %w", s, ErrMalformedKey)
+	}
+	return p, nil
+}
+
+// ParseDotPath parses a path in dot notation such as "input4.key2.1", see ParsePath().
+func ParseDotPath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	keys := strings.Split(s, ".")
+	p := make(Path, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("%s: %w", s, ErrMalformedKey)
+		}
+		if i == 0 {
+			p[i] = k
+		} else {
+			p[i] = pathElement(k)
+		}
+	}
+	return p, nil
+}
+
+// returns k as an int if it is a canonical non-negative integer, k otherwise.
+func pathElement(k string) any {
+	if i, err := strconv.Atoi(k); err == nil && i >= 0 && strconv.Itoa(i) == k {
+		return i
+	}
+	return k
+}
+
+// String formats p as a bracket path, "input4[key2][1]".
+func (p Path) String() string {
+	key := ""
+	for _, k := range p {
+		key = pathKey(key, k)
+	}
+	return key
+}
+
+// DotString formats p in dot notation, "input4.key2.1".
+func (p Path) DotString() string {
+	keys := make([]string, len(p))
+	for i, k := range p {
+		keys[i] = fmt.Sprint(k)
+	}
+	return strings.Join(keys, ".")
+}
+
+// Append returns a copy of p with keys appended.
+func (p Path) Append(keys ...any) Path {
+	out := make(Path, 0, len(p)+len(keys))
+	return append(append(out, p...), keys...)
+}
+
+// descends val following p, a slice index addressing a ValueMap is used as a map key
+// so that paths parsed from maps with numeric keys resolve.
+func (val *item) resolvePath(op string, p Path) (Value, error) {
+	current := val
+	for _, k := range p {
+		if i, ok := k.(int); ok && current.Is(ValueMap) {
+			k = strconv.Itoa(i)
+		}
+		next, err := current.child(k, false)
+		if err != nil {
+			key := pathKey(current.key, k)
+			return newNilValue(key), &PathError{Op: op, Path: key, Err: err}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func (val *item) GetPath(path string) (Value, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return newNilValue(path), &PathError{Op: "GetPath", Path: path, Err: err}
+	}
+	return val.resolvePath("GetPath", p)
+}
+
+func (val *item) GetDotPath(path string) (Value, error) {
+	p, err := ParseDotPath(path)
+	if err != nil {
+		return newNilValue(path), &PathError{Op: "GetDotPath", Path: path, Err: err}
+	}
+	return val.resolvePath("GetDotPath", p)
+}
@@ -0,0 +1,127 @@
+package url_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		src    string
+		dot    bool
+		expect URL.Path
+	}{
+		{"input4[key2][1]", false, URL.Path{"input4", "key2", 1}},
+		{"input4", false, URL.Path{"input4"}},
+		{"a[01][-1]", false, URL.Path{"a", "01", "-1"}},
+		{"0[0]", false, URL.Path{"0", 0}},
+		{"a[b", false, URL.Path{"a[b"}},
+		{"", false, URL.Path{}},
+		{"input4.key2.1", true, URL.Path{"input4", "key2", 1}},
+		{"0.0.x", true, URL.Path{"0", 0, "x"}},
+	}
+	for _, test := range tests {
+		parse := URL.ParsePath
+		if test.dot {
+			parse = URL.ParseDotPath
+		}
+		p, err := parse(test.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, test.expect) {
+			t.Errorf("%s: expected %#v found %#v", test.src, test.expect, p)
+		}
+	}
+
+	for _, src := range []string{"a[]", "a[b]c", "a[b][]", "a[x]]y[", "a]b[c]"} {
+		if _, err := URL.ParsePath(src); !errors.Is(err, URL.ErrMalformedKey) {
+			t.Errorf("%s: expected ErrMalformedKey found %v", src, err)
+		}
+	}
+	for _, src := range []string{"a..b", ".a", "a."} {
+		if _, err := URL.ParseDotPath(src); !errors.Is(err, URL.ErrMalformedKey) {
+			t.Errorf("%s: expected ErrMalformedKey found %v", src, err)
+		}
+	}
+}
+
+func TestPathFormat(t *testing.T) {
+	p := URL.Path{"input4", "key2", 1}
+	if s := p.String(); s != "input4[key2][1]" {
+		t.Errorf("String: unexpected %q", s)
+	}
+	if s := p.DotString(); s != "input4.key2.1" {
+		t.Errorf("DotString: unexpected %q", s)
+	}
+
+	q := p.Append("x", 0)
+	if s := q.String(); s != "input4[key2][1][x][0]" {
+		t.Errorf("Append: unexpected %q", s)
+	}
+	if len(p) != 3 {
+		t.Errorf("Append modified the receiver: %#v", p)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		mapV, err := URL.ParseQuery("a[b][0][c]=1&a[b][1][]=2&x=3&m[0]=4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range [][]any{{"a", "b", 0, "c"}, {"a", "b", 1, 0}, {"x"}} {
+			v, err := mapV.GetValue(path...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := URL.ParsePath(v.Key())
+			if err != nil {
+				t.Fatalf("%s: %v", v.Key(), err)
+			}
+			if p.String() != v.Key() {
+				t.Errorf("expected %q found %q", v.Key(), p.String())
+			}
+			got, err := mapV.GetValue(p...)
+			if err != nil || got != v {
+				t.Errorf("%s: GetValue did not return the same value (%v)", v.Key(), err)
+			}
+		}
+	})
+}
+
+func TestGetPath(t *testing.T) {
+	mapV, err := URL.ParseQuery("input4[key2][]=a&input4[key2][]=b&m[0]=zero")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := mapV.GetPath("input4[key2][1]")
+	if s, _ := v.String(); err != nil || s != "b" {
+		t.Errorf("GetPath: expected b found %q (%v)", s, err)
+	}
+	v, err = mapV.GetDotPath("input4.key2.0")
+	if s, _ := v.String(); err != nil || s != "a" {
+		t.Errorf("GetDotPath: expected a found %q (%v)", s, err)
+	}
+	v, err = mapV.GetPath("m[0]")
+	if s, _ := v.String(); err != nil || s != "zero" {
+		t.Errorf("GetPath numeric map key: expected zero found %q (%v)", s, err)
+	}
+
+	var pathErr *URL.PathError
+	if _, err := mapV.GetPath("input4[key2][5]"); !errors.As(err, &pathErr) || !errors.Is(err, URL.ErrKeyNotFound) || pathErr.Path != "input4[key2][5]" {
+		t.Errorf("GetPath: unexpected error %v", err)
+	}
+	if _, err := mapV.GetDotPath("input4.key2.x"); !errors.Is(err, URL.ErrValueNotMap) {
+		t.Errorf("GetDotPath: expected ErrValueNotMap found %v", err)
+	}
+	if _, err := mapV.GetPath("input4[x]]y["); !errors.Is(err, URL.ErrMalformedKey) {
+		t.Errorf("expected ErrMalformedKey, found %v", err)
+	}
+	if _, err := mapV.GetPath("input4[]"); !errors.Is(err, URL.ErrMalformedKey) {
+		t.Errorf("GetPath: expected ErrMalformedKey found %v", err)
+	}
+}
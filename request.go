@@ -0,0 +1,216 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Source identifies the part of an *http.Request a Value was parsed from, see ParseRequest.
+type Source int
+
+const (
+	// the Value was not parsed by ParseRequest
+	SourceNone Source = iota
+	// query string, 'G' in RequestOptions.Order
+	SourceQuery
+	// urlencoded or multipart body, 'P' in RequestOptions.Order
+	SourceBody
+	// cookies, 'C' in RequestOptions.Order
+	SourceCookie
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceQuery:
+		return "query"
+	case SourceBody:
+		return "body"
+	case SourceCookie:
+		return "cookie"
+	}
+	return "none"
+}
+
+// DefaultMaxBodyBytes is the size limit applied to request bodies when RequestOptions.MaxBodyBytes is zero,
+// the same limit r.ParseForm() applies to urlencoded bodies.
+const DefaultMaxBodyBytes = 10 << 20
+
+// Default limits applied by ParseRequest to every source when the ParseOptions limit is zero,
+// they follow PHP's max_input_nesting_level and max_input_vars.
+const (
+	DefaultMaxDepth      = 64
+	DefaultMaxSliceIndex = 1000
+	DefaultMaxKeys       = 1000
+	DefaultMaxNodes      = 10000
+)
+
+// RequestOptions defines the sources merged by ParseRequest and the options used to parse them.
+type RequestOptions struct {
+	// MultipartOptions are applied to every source, limits are enforced on each source separately.
+	// MaxDepth, MaxSliceIndex, MaxKeys and MaxNodes left at zero apply the Default limits, a negative limit is not enforced.
+	MultipartOptions
+	// Order lists the sources to parse as PHP's variables_order does, 'G' the query string, 'P' the body
+	// and 'C' the cookies. Sources listed later replace the root keys of the earlier ones. Defaults to "GP".
+	Order string
+	// MaxBodyBytes is the maximum size of the body, it is enforced using http.MaxBytesReader.
+	// Zero applies DefaultMaxBodyBytes, a negative value does not limit the body.
+	MaxBodyBytes int64
+}
+
+// ParseRequest parses the sources of r listed in opts.Order and merges them into a single Map, as PHP's $_REQUEST.
+//
+//	// POST /search?q=go&page=1 with body "page=2&filters[lang]=en"
+//	mapV, err := url.ParseRequest(r, url.RequestOptions{Order: "GP"})
+//	mapV.GetString("page") // "2", the body is listed after the query string
+//	v, _ := mapV.GetValue("filters", "lang")
+//	v.Source() // SourceBody
+//
+// Sources are merged by root key, "filters[lang]" in the body replaces the whole "filters" of the query string.
+// The body is parsed for POST, PUT and PATCH requests with an application/x-www-form-urlencoded or
+// multipart/form-data content type, other bodies are ignored. If r.ParseForm() or r.ParseMultipartForm() were
+// already called, r.PostForm and r.MultipartForm are parsed instead of the body, multipart bodies left unread
+// by r.ParseForm() are parsed.
+// Cookie values are not unescaped, as http.Request.Cookies() does.
+//
+// If the body is larger than opts.MaxBodyBytes, DefaultMaxBodyBytes if not set, or than the limit of an
+// http.MaxBytesReader wrapping r.Body, a *LimitError with Limit "MaxBodyBytes" is returned. Other errors are
+// returned as the parser of each source does, in strict mode the errors of every source are reported in a single *ParseErrors.
+//
+// Unlike the other parsers ParseRequest enforces DefaultMaxDepth, DefaultMaxSliceIndex, DefaultMaxKeys and DefaultMaxNodes
+// on the query string, the body and the cookies when the ParseOptions limits are not set, "a[2000000]=x" is rejected.
+func ParseRequest(r *http.Request, opts RequestOptions) (m Map, err error) {
+	opts.ParseOptions = defaultLimits(opts.ParseOptions)
+	order := opts.Order
+	if order == "" {
+		order = "GP"
+	}
+	out := newNilValue("").to(ValueMap)
+	root := out.(*item).value.(*orderedMap)
+	var errs []*ParseError
+	var first error
+	for _, c := range order {
+		var src Source
+		var sm Map
+		var serr error
+		switch c {
+		case 'G':
+			src = SourceQuery
+			sm, serr = ParseQueryWithOptions(r.URL.RawQuery, opts.ParseOptions)
+		case 'P':
+			src = SourceBody
+			sm, serr = parseBody(r, opts)
+		case 'C':
+			src = SourceCookie
+			sm, serr = newParser(opts.ParseOptions).parse(cookieFields(r))
+		default:
+			return nil, fmt.Errorf("unknown request source %q", c)
+		}
+		if sm == nil {
+			if serr == nil {
+				continue
+			}
+			var maxErr *http.MaxBytesError
+			if errors.As(serr, &maxErr) {
+				return nil, &LimitError{Limit: "MaxBodyBytes", Max: int(maxErr.Limit), Key: ""}
+			}
+			return nil, serr
+		}
+		if perrs, ok := serr.(*ParseErrors); ok {
+			errs = append(errs, perrs.Errors...)
+		} else if serr != nil && first == nil {
+			first = serr
+		}
+		val, _ := sm.GetValue()
+		for _, e := range entries(val, OrderInsertion) {
+			setSource(e.value.(*item), src)
+			root.set(e.key.(string), e.value)
+		}
+	}
+	if len(errs) > 0 {
+		return out, &ParseErrors{Errors: errs}
+	}
+	return out, first
+}
+
+// returns opts applying the Default limits to the limits set to zero.
+func defaultLimits(opts ParseOptions) ParseOptions {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	if opts.MaxSliceIndex == 0 {
+		opts.MaxSliceIndex = DefaultMaxSliceIndex
+	}
+	if opts.MaxKeys == 0 {
+		opts.MaxKeys = DefaultMaxKeys
+	}
+	if opts.MaxNodes == 0 {
+		opts.MaxNodes = DefaultMaxNodes
+	}
+	return opts
+}
+
+// parses the urlencoded or multipart body of r, returns a nil Map if r has no form body.
+func parseBody(r *http.Request, opts RequestOptions) (m Map, err error) {
+	if r.MultipartForm != nil {
+		return ParseMultipartWithOptions(r.MultipartForm, opts.MultipartOptions)
+	}
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	// r.ParseForm() leaves multipart bodies unread and r.PostForm empty
+	if r.PostForm != nil && mediaType != "multipart/form-data" {
+		return ParseValuesWithOptions(r.PostForm, opts.ParseOptions)
+	}
+	if r.Body == nil || (r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch) {
+		return nil, nil
+	}
+	body := r.Body
+	switch {
+	case opts.MaxBodyBytes == 0:
+		body = http.MaxBytesReader(nil, r.Body, DefaultMaxBodyBytes)
+	case opts.MaxBodyBytes > 0:
+		body = http.MaxBytesReader(nil, r.Body, opts.MaxBodyBytes)
+	}
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return ParseReaderWithOptions(body, opts.ParseOptions)
+	case "multipart/form-data":
+		boundary, ok := params["boundary"]
+		if !ok {
+			return nil, errors.New("multipart boundary not found")
+		}
+		return ParseMultipartReader(body, boundary, opts.MultipartOptions)
+	}
+	return nil, nil
+}
+
+// returns the cookies of r in the order they were sent.
+// Cookies are read from the header as http.Request.Cookies() would discard names holding brackets.
+func cookieFields(r *http.Request) (fields []field) {
+	for _, line := range r.Header.Values("Cookie") {
+		for _, pair := range strings.Split(line, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if name == "" {
+				continue
+			}
+			if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+				value = value[1 : len(value)-1]
+			}
+			fields = append(fields, field{key: name, values: []string{value}})
+		}
+	}
+	return
+}
+
+// sets the source of val and of its elements.
+func setSource(val *item, src Source) {
+	val.source = src
+	for _, e := range entries(val, OrderInsertion) {
+		setSource(e.value.(*item), src)
+	}
+}
+
+func (val *item) Source() Source {
+	return val.source
+}
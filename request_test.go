@@ -0,0 +1,187 @@
+package url_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func testFormRequest(target, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestParseRequest(t *testing.T) {
+	t.Run("default order", func(t *testing.T) {
+		r := testFormRequest("/search?q=go&page=1&filters[lang]=it", "page=2&filters[size]=10")
+		r.AddCookie(&http.Cookie{Name: "q", Value: "cookie"})
+		m, err := URL.ParseRequest(r, URL.RequestOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetString("page"); got != "2" {
+			t.Errorf("page: expected 2, found %q", got)
+		}
+		if got := m.GetString("q"); got != "go" {
+			t.Errorf("q: expected go, found %q", got)
+		}
+		// root keys are replaced, not merged
+		if v, _ := m.GetValue("filters", "lang"); !v.IsNil() {
+			t.Errorf("filters[lang]: expected ValueNil, found %s", v.Type())
+		}
+		v, _ := m.GetValue("filters", "size")
+		if v.Source() != URL.SourceBody {
+			t.Errorf("filters[size]: expected source body, found %s", v.Source())
+		}
+		if v.Key() != "filters[size]" {
+			t.Errorf("expected key filters[size], found %s", v.Key())
+		}
+		if v, _ := m.GetValue("q"); v.Source() != URL.SourceQuery {
+			t.Errorf("q: expected source query, found %s", v.Source())
+		}
+	})
+	t.Run("custom order", func(t *testing.T) {
+		r := testFormRequest("/?a=query&b=query", "a=body")
+		r.AddCookie(&http.Cookie{Name: "a", Value: "cookie"})
+		r.AddCookie(&http.Cookie{Name: "c[x]", Value: "cookie"})
+		m, err := URL.ParseRequest(r, URL.RequestOptions{Order: "PCG"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetString("a"); got != "query" {
+			t.Errorf("a: expected query, found %q", got)
+		}
+		v, _ := m.GetValue("c", "x")
+		if s, _ := v.String(); s != "cookie" || v.Source() != URL.SourceCookie {
+			t.Errorf("c[x]: expected cookie from cookie, found %q from %s", s, v.Source())
+		}
+		root, _ := m.GetValue()
+		if got := root.StringKeys(); strings.Join(got, ",") != "a,c,b" {
+			t.Errorf("expected keys a,c,b, found %v", got)
+		}
+	})
+	t.Run("multipart", func(t *testing.T) {
+		buf, boundary := testMultipart(t, [3]string{"doc[title]", "", "Invoice"}, [3]string{"doc[file]", "invoice.txt", "content"})
+		r := httptest.NewRequest(http.MethodPost, "/?doc=query", buf)
+		r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		m, err := URL.ParseRequest(r, URL.RequestOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := m.GetValue("doc", "file")
+		if got := testFileContent(t, v); got != "content" {
+			t.Errorf("expected content, found %q", got)
+		}
+		if v.Source() != URL.SourceBody {
+			t.Errorf("expected source body, found %s", v.Source())
+		}
+	})
+	t.Run("parsed form", func(t *testing.T) {
+		r := testFormRequest("/", "a[]=1&a[]=2")
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		m, err := URL.ParseRequest(r, URL.RequestOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetStrings("a"); strings.Join(got, ",") != "1,2" {
+			t.Errorf("expected 1,2, found %v", got)
+		}
+	})
+	t.Run("parsed form multipart", func(t *testing.T) {
+		buf, boundary := testMultipart(t, [3]string{"name", "", "John"})
+		r := httptest.NewRequest(http.MethodPost, "/", buf)
+		r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		m, err := URL.ParseRequest(r, URL.RequestOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetString("name"); got != "John" {
+			t.Errorf("expected John, found %q", got)
+		}
+	})
+	t.Run("ignored body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/?a=1", strings.NewReader(`{"a":2}`))
+		r.Header.Set("Content-Type", "application/json")
+		m, err := URL.ParseRequest(r, URL.RequestOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetString("a"); got != "1" {
+			t.Errorf("expected 1, found %q", got)
+		}
+		r = httptest.NewRequest(http.MethodGet, "/", strings.NewReader("a=2"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		m, _ = URL.ParseRequest(r, URL.RequestOptions{})
+		if root, _ := m.GetValue(); root.Len() != 0 {
+			t.Errorf("expected GET body to be ignored, found %d keys", root.Len())
+		}
+	})
+	t.Run("body limit", func(t *testing.T) {
+		body := "a=" + strings.Repeat("x", 100)
+		var lerr *URL.LimitError
+		_, err := URL.ParseRequest(testFormRequest("/", body), URL.RequestOptions{MaxBodyBytes: 10})
+		if !errors.As(err, &lerr) || lerr.Limit != "MaxBodyBytes" || lerr.Max != 10 {
+			t.Errorf("expected MaxBodyBytes LimitError, found %v", err)
+		}
+		r := testFormRequest("/", body)
+		r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 20)
+		if _, err = URL.ParseRequest(r, URL.RequestOptions{}); !errors.Is(err, URL.ErrLimitExceeded) {
+			t.Errorf("expected ErrLimitExceeded, found %v", err)
+		}
+		buf, boundary := testMultipart(t, [3]string{"f", "f.txt", strings.Repeat("x", 100)})
+		r = httptest.NewRequest(http.MethodPost, "/", buf)
+		r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		if _, err = URL.ParseRequest(r, URL.RequestOptions{MaxBodyBytes: 50}); !errors.Is(err, URL.ErrLimitExceeded) {
+			t.Errorf("expected ErrLimitExceeded, found %v", err)
+		}
+		body = "a=" + strings.Repeat("x", URL.DefaultMaxBodyBytes)
+		_, err = URL.ParseRequest(testFormRequest("/", body), URL.RequestOptions{})
+		if !errors.As(err, &lerr) || lerr.Max != URL.DefaultMaxBodyBytes {
+			t.Errorf("expected DefaultMaxBodyBytes LimitError, found %v", err)
+		}
+		if _, err = URL.ParseRequest(testFormRequest("/", body), URL.RequestOptions{MaxBodyBytes: -1}); err != nil {
+			t.Errorf("expected no limit, found %v", err)
+		}
+	})
+	t.Run("default limits", func(t *testing.T) {
+		var lerr *URL.LimitError
+		r := httptest.NewRequest(http.MethodGet, "/?a[2000000]=x", nil)
+		if _, err := URL.ParseRequest(r, URL.RequestOptions{}); !errors.As(err, &lerr) || lerr.Limit != "MaxSliceIndex" || lerr.Max != URL.DefaultMaxSliceIndex {
+			t.Errorf("expected MaxSliceIndex LimitError, found %v", err)
+		}
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Cookie", "a"+strings.Repeat("[x]", URL.DefaultMaxDepth+1)+"=1")
+		if _, err := URL.ParseRequest(r, URL.RequestOptions{Order: "GPC"}); !errors.As(err, &lerr) || lerr.Limit != "MaxDepth" {
+			t.Errorf("expected MaxDepth LimitError, found %v", err)
+		}
+		r = testFormRequest("/", "a[1500]=x")
+		opts := URL.RequestOptions{}
+		opts.MaxSliceIndex = 2000
+		if _, err := URL.ParseRequest(r, opts); err != nil {
+			t.Errorf("expected the explicit limit to apply, found %v", err)
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		r := testFormRequest("/?a[0]=1&a[x]=2", "b[0]=1&b[x]=2")
+		var perrs *URL.ParseErrors
+		_, err := URL.ParseRequest(r, URL.RequestOptions{MultipartOptions: URL.MultipartOptions{ParseOptions: URL.ParseOptions{Strict: true}}})
+		if !errors.As(err, &perrs) || len(perrs.Errors) != 2 {
+			t.Errorf("expected 2 errors, found %v", err)
+		}
+	})
+	t.Run("unknown source", func(t *testing.T) {
+		if _, err := URL.ParseRequest(testFormRequest("/", ""), URL.RequestOptions{Order: "GX"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
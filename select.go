@@ -0,0 +1,56 @@
+package url
+
+// Select segments matching multiple elements.
+const (
+	selectAny       = "*"
+	selectRecursive = "**"
+)
+
+func (val *item) Select(path ...any) []Value {
+	current := []*item{val}
+	for _, k := range path {
+		var next []*item
+		for _, v := range current {
+			switch k {
+			case selectAny:
+				for _, e := range entries(v, OrderSorted) {
+					next = append(next, e.value.(*item))
+				}
+			case selectRecursive:
+				next = descendants(v, next)
+			default:
+				if c, err := v.child(k, false); err == nil {
+					next = append(next, c)
+				}
+			}
+		}
+		current = unique(next)
+	}
+	out := make([]Value, len(current))
+	for i, v := range current {
+		out[i] = v
+	}
+	return out
+}
+
+// appends val and its descendants to list in pre-order, map keys sorted.
+func descendants(val *item, list []*item) []*item {
+	list = append(list, val)
+	for _, e := range entries(val, OrderSorted) {
+		list = descendants(e.value.(*item), list)
+	}
+	return list
+}
+
+// removes duplicates from list keeping the first occurrence.
+func unique(list []*item) []*item {
+	seen := make(map[*item]bool, len(list))
+	out := list[:0]
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
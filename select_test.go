@@ -0,0 +1,78 @@
+package url_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func selectKeys(values []URL.Value) []string {
+	keys := make([]string, len(values))
+	for i, v := range values {
+		keys[i] = v.Key()
+	}
+	return keys
+}
+
+func TestSelect(t *testing.T) {
+	mapV, err := URL.ParseQuery("rows[0][name]=a&rows[0][id]=1&rows[1][name]=b&rows[2][id]=3&meta[id]=9&meta[x][id]=7&s=v")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   []any
+		expect []string
+	}{
+		{[]any{"rows", "*", "name"}, []string{"rows[0][name]", "rows[1][name]"}},
+		{[]any{"rows", 1, "name"}, []string{"rows[1][name]"}},
+		{[]any{"meta", "*"}, []string{"meta[id]", "meta[x]"}},
+		{[]any{"**", "id"}, []string{"meta[id]", "meta[x][id]", "rows[0][id]", "rows[2][id]"}},
+		{[]any{"**", "**", "id"}, []string{"meta[id]", "meta[x][id]", "rows[0][id]", "rows[2][id]"}},
+		{[]any{"rows", "**"}, []string{"rows", "rows[0]", "rows[0][id]", "rows[0][name]", "rows[1]", "rows[1][name]", "rows[2]", "rows[2][id]"}},
+		{[]any{"s", "*"}, []string{}},
+		{[]any{"missing", "*"}, []string{}},
+		{[]any{}, []string{""}},
+	}
+	for _, test := range tests {
+		found := selectKeys(mapV.Select(test.path...))
+		if !reflect.DeepEqual(found, test.expect) {
+			t.Errorf("%v: expected %v found %v", test.path, test.expect, found)
+		}
+	}
+
+	names := mapV.Select("rows", "*", "name")
+	if s, _ := names[1].String(); s != "b" {
+		t.Errorf("rows[1][name]: expected b found %q", s)
+	}
+}
+
+func TestGetValueErrors(t *testing.T) {
+	mapV, err := URL.ParseQuery("a[b][]=1&s=v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path []any
+		err  error
+		key  string
+	}{
+		{[]any{"a", "b", 3}, URL.ErrKeyNotFound, "a[b][3]"},
+		{[]any{"a", "b", -1}, URL.ErrInvalidIndex, "a[b][-1]"},
+		{[]any{"a", 0}, URL.ErrValueNotSlice, "a[0]"},
+		{[]any{"s", "x"}, URL.ErrValueNotMap, "s[x]"},
+		{[]any{"missing"}, URL.ErrKeyNotFound, "missing"},
+	}
+	for _, test := range tests {
+		v, err := mapV.GetValue(test.path...)
+		var perr *URL.PathError
+		if !errors.As(err, &perr) || !errors.Is(err, test.err) || perr.Path != test.key {
+			t.Errorf("%v: expected %v at %s, found %v", test.path, test.err, test.key, err)
+		}
+		if !v.IsNil() {
+			t.Errorf("%v: expected ValueNil found %s", test.path, v.Type())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package url
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField describes a struct field bound to a key by the `url:"..."` tag.
+//
+//	type Form struct {
+//		Name    string            `url:"name"`
+//		Tags    []string          `url:"tags,omitempty"`
+//		Email   string            `url:"email,required"`
+//		Address *Address          `url:"address"`
+//		Meta    map[string]string // key "Meta"
+//		Ignored string            `url:"-"`
+//	}
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	required  bool
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// returns the fields of t that are bound to a key.
+//
+// Fields of embedded structs without a tag are promoted to t, when names collide
+// the least nested field wins.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	seen := make(map[string]int)
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		var embedded []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag, hasTag := sf.Tag.Lookup("url")
+			if tag == "-" {
+				continue
+			}
+			if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+				embedded = append(embedded, sf)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = sf.Name
+			}
+			fieldIndex := append(append([]int{}, index...), i)
+			if pos, ok := seen[name]; ok {
+				if len(fields[pos].index) <= len(fieldIndex) {
+					continue
+				}
+				fields[pos] = structField{name: name, index: fieldIndex, omitEmpty: hasOption(opts, "omitempty"), required: hasOption(opts, "required")}
+				continue
+			}
+			seen[name] = len(fields)
+			fields = append(fields, structField{name: name, index: fieldIndex, omitEmpty: hasOption(opts, "omitempty"), required: hasOption(opts, "required")})
+		}
+		for _, sf := range embedded {
+			collect(sf.Type, append(append([]int{}, index...), sf.Index...))
+		}
+	}
+	collect(t, nil)
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// returns true if option is listed in the comma separated opts.
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
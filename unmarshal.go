@@ -0,0 +1,296 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var valueInterfaceType = reflect.TypeOf((*Value)(nil)).Elem()
+var mapInterfaceType = reflect.TypeOf((*Map)(nil)).Elem()
+var fileType = reflect.TypeOf(File{})
+
+// UnmarshalError describes a Value that cannot be stored in a Go value.
+type UnmarshalError struct {
+	// Path is the bracket path of the Value, as returned by Value.Key()
+	Path string
+	// Type is the Go type the Value could not be stored into
+	Type reflect.Type
+	// Err is the underlying error
+	Err error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("%s: cannot unmarshal into %s: %v", e.Path, e.Type, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal stores the content of m into the value pointed by v.
+//
+// Struct fields are bound to keys using the `url:"name"` tag, fields without a tag use the field name,
+// fields tagged `url:"-"` are ignored. Fields of embedded structs are promoted as encoding/json does.
+//
+//	type Address struct {
+//		City string `url:"city"`
+//	}
+//	type Form struct {
+//		Name      string            `url:"name"`
+//		Tags      []string          `url:"tags"`
+//		Address   *Address          `url:"address"`
+//		Meta      map[string]string `url:"meta"`
+//		Subscribe bool              `url:"subscribe"`
+//	}
+//
+//	// name=John&tags[]=a&tags[]=b&address[city]=Rome&meta[k]=v&subscribe=on
+//	var form Form
+//	err := url.Unmarshal(mapV, &form)
+//
+// ValueMap is stored into structs and maps with string keys, ValueSlice into slices and arrays,
+// ValueArray into either of them, see Value.ToMap() and Value.ToSlice(),
+// ValueString into strings, booleans (HTML checkbox semantics), numbers, time.Duration,
+// types implementing encoding.TextUnmarshaler and types registered with RegisterConverter.
+// A ValueString stored into a slice results in a single element, a ValueSlice longer than
+// the array it is stored into is an error.
+// Pointers are allocated as needed, ValueNil and missing keys leave the Go value untouched.
+// Fields of type Value or Map receive the Value itself, interface{} fields receive
+// map[string]any, []any, string or *File. ValueFile is stored into File and *File only.
+//
+// If a Value cannot be stored Unmarshal stops and returns an *UnmarshalError naming the bracket path of the Value.
+func Unmarshal(m Map, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Unmarshal requires a non-nil pointer, found %T", v)
+	}
+	if m == nil {
+		return nil
+	}
+	root, err := m.GetValue()
+	if err != nil {
+		return err
+	}
+	return (&decoder{}).value(root, rv.Elem())
+}
+
+// UnmarshalValues parses src with ParseValues and stores the result into the value pointed by v, see Unmarshal.
+func UnmarshalValues(src url.Values, v any) error {
+	m, err := ParseValues(src)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(m, v)
+}
+
+// decoder stores Values into Go values.
+type decoder struct {
+	// collect records the UnmarshalErrors found in the elements of a container and continues,
+	// by default the first error stops the decoder.
+	collect bool
+	// emptyAsZero stores an empty ValueString into a number as the zero value, as Bind does.
+	emptyAsZero bool
+	errs        []*UnmarshalError
+}
+
+// returns err if it stops the decoder, nil if it is collected.
+func (d *decoder) fail(err error) error {
+	var uerr *UnmarshalError
+	if d.collect && errors.As(err, &uerr) {
+		d.errs = append(d.errs, uerr)
+		return nil
+	}
+	return err
+}
+
+// stores val into rv.
+func (d *decoder) value(val Value, rv reflect.Value) error {
+	if val.IsNil() {
+		return nil
+	}
+	if rv.Type() == valueInterfaceType || rv.Type() == mapInterfaceType {
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.value(val, rv.Elem())
+	}
+	if rv.Kind() == reflect.Interface {
+		if rv.NumMethod() != 0 {
+			return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: errors.New("non-empty interface")}
+		}
+		rv.Set(reflect.ValueOf(toAny(val)))
+		return nil
+	}
+
+	if f, ok := val.File(); ok {
+		if rv.Type() != fileType {
+			return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: errors.New("Value is a file")}
+		}
+		rv.Set(reflect.ValueOf(*f))
+		return nil
+	}
+	if val.Is(ValueArray) {
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			return d.elements(val, val.ToSlice(), rv)
+		}
+		return d.fields(val, val.ToMap(), rv)
+	}
+
+	switch val.Type() {
+	case ValueString:
+		s, _ := val.String()
+		if _, ok := converters.Load(rv.Type()); ok {
+			break
+		}
+		if rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) {
+			break
+		}
+		if d.emptyAsZero && s == "" && isNumber(rv.Kind()) {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes([]byte(s))
+			return nil
+		}
+		if rv.Kind() == reflect.Slice {
+			// a single value submitted for a slice
+			slice := reflect.MakeSlice(rv.Type(), 1, 1)
+			if err := d.value(val, slice.Index(0)); err != nil {
+				return err
+			}
+			rv.Set(slice)
+			return nil
+		}
+	case ValueMap:
+		m, _ := mapElements(val)
+		return d.fields(val, m, rv)
+	case ValueSlice:
+		s, _ := val.Slice()
+		return d.elements(val, s, rv)
+	}
+
+	s, _ := val.String()
+	if err := convertString(s, rv); err != nil {
+		return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: err}
+	}
+	return nil
+}
+
+// stores the elements m of the container val into the struct or map rv.
+func (d *decoder) fields(val Value, m map[string]Value, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(rv.Type()) {
+			if nested, ok := m[f.name]; ok {
+				if err := d.fail(d.value(nested, rv.FieldByIndex(f.index))); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case reflect.Map:
+		t := rv.Type()
+		if t.Key().Kind() != reflect.String {
+			return &UnmarshalError{Path: val.Key(), Type: t, Err: errors.New("map key is not a string")}
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(t, len(m)))
+		}
+		for _, k := range sortedKeys(m) {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := d.fail(d.value(m[k], elem)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), elem)
+		}
+		return nil
+	}
+	return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: fmt.Errorf("Value is a %s", val.Type())}
+}
+
+// stores the elements s of the container val into the slice or array rv.
+func (d *decoder) elements(val Value, s []Value, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(rv.Type(), len(s), len(s))
+		for i, nested := range s {
+			if err := d.fail(d.value(nested, slice.Index(i))); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(s) > rv.Len() {
+			return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: fmt.Errorf("%d elements found", len(s))}
+		}
+		rv.Set(reflect.Zero(rv.Type()))
+		for i, nested := range s {
+			if err := d.fail(d.value(nested, rv.Index(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &UnmarshalError{Path: val.Key(), Type: rv.Type(), Err: fmt.Errorf("Value is a %s", val.Type())}
+}
+
+// reports whether k is an integer or floating point kind.
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// converts val into map[string]any, []any, string, *File or nil.
+// ValueArray is converted as a ValueSlice if its keys are 0 to Len()-1 in order, as a ValueMap otherwise.
+func toAny(val Value) any {
+	switch val.Type() {
+	case ValueString:
+		s, _ := val.String()
+		return s
+	case ValueSlice:
+		s, _ := val.Slice()
+		out := make([]any, len(s))
+		for i, nested := range s {
+			out[i] = toAny(nested)
+		}
+		return out
+	case ValueFile:
+		f, _ := val.File()
+		return f
+	case ValueArray:
+		if isList(val) {
+			s := val.ToSlice()
+			out := make([]any, len(s))
+			for i, nested := range s {
+				out[i] = toAny(nested)
+			}
+			return out
+		}
+		out := make(map[string]any, val.Len())
+		for k, nested := range val.ToMap() {
+			out[k] = toAny(nested)
+		}
+		return out
+	case ValueMap:
+		m, _ := mapElements(val)
+		out := make(map[string]any, len(m))
+		for k, nested := range m {
+			out[k] = toAny(nested)
+		}
+		return out
+	}
+	return nil
+}
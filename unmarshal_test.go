@@ -0,0 +1,151 @@
+package url_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	URL "github.com/thetechpanda/url"
+)
+
+type testAddress struct {
+	City string `url:"city"`
+	Zip  int    `url:"zip"`
+}
+
+type testEmbedded struct {
+	Source string `url:"source"`
+}
+
+type testForm struct {
+	testEmbedded
+	Name      string            `url:"name"`
+	Age       uint8             `url:"age"`
+	Score     float64           `url:"score"`
+	Subscribe bool              `url:"subscribe"`
+	Tags      []string          `url:"tags"`
+	Pair      [2]int            `url:"pair"`
+	Address   *testAddress      `url:"address"`
+	Addresses []testAddress     `url:"addresses"`
+	Meta      map[string]string `url:"meta"`
+	Timeout   time.Duration     `url:"timeout"`
+	When      time.Time         `url:"when"`
+	Raw       URL.Value         `url:"raw"`
+	Any       any               `url:"any"`
+	Missing   *string           `url:"missing"`
+	Ignored   string            `url:"-"`
+	Untagged  string
+}
+
+func TestUnmarshal(t *testing.T) {
+	src := url.Values{
+		"source":              {"web"},
+		"name":                {"John"},
+		"age":                 {"42"},
+		"score":               {"9.5"},
+		"subscribe":           {"on"},
+		"tags[]":              {"a", "b"},
+		"pair[0]":             {"1"},
+		"pair[1]":             {"2"},
+		"address[city]":       {"Rome"},
+		"address[zip]":        {"100"},
+		"addresses[0][city]":  {"Milan"},
+		"addresses[1][city]":  {"Turin"},
+		"meta[k1]":            {"v1"},
+		"meta[k2]":            {"v2"},
+		"timeout":             {"1m30s"},
+		"when":                {"2024-01-02T03:04:05Z"},
+		"raw[a]":              {"b"},
+		"any[]":               {"x"},
+		"-":                   {"ignored"},
+		"Untagged":            {"untagged"},
+		"not[part][of][form]": {"x"},
+	}
+	var form testForm
+	if err := URL.UnmarshalValues(src, &form); err != nil {
+		t.Fatal(err)
+	}
+	expected := testForm{
+		testEmbedded: testEmbedded{Source: "web"},
+		Name:         "John",
+		Age:          42,
+		Score:        9.5,
+		Subscribe:    true,
+		Tags:         []string{"a", "b"},
+		Pair:         [2]int{1, 2},
+		Address:      &testAddress{City: "Rome", Zip: 100},
+		Addresses:    []testAddress{{City: "Milan"}, {City: "Turin"}},
+		Meta:         map[string]string{"k1": "v1", "k2": "v2"},
+		Timeout:      90 * time.Second,
+		When:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Any:          []any{"x"},
+		Untagged:     "untagged",
+	}
+	if form.Raw == nil || form.Raw.GetString("a") != "b" {
+		t.Errorf("raw: expected the ValueMap to be stored")
+	}
+	form.Raw = nil
+	if !reflect.DeepEqual(form, expected) {
+		t.Errorf("expected %+v\nfound %+v", expected, form)
+	}
+
+	t.Run("single value into slice", func(t *testing.T) {
+		var out struct {
+			Tags []string `url:"tags"`
+		}
+		if err := URL.UnmarshalValues(url.Values{"tags": {"a"}}, &out); err != nil || len(out.Tags) != 1 || out.Tags[0] != "a" {
+			t.Errorf("expected [a], found %v (%v)", out.Tags, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var tests = []struct {
+			src  url.Values
+			path string
+		}{
+			{url.Values{"addresses[1][zip]": {"abc"}}, "addresses[1][zip]"},
+			{url.Values{"age": {"300"}}, "age"},
+			{url.Values{"subscribe": {"maybe"}}, "subscribe"},
+			{url.Values{"name[first]": {"John"}}, "name"},
+			{url.Values{"pair[]": {"1", "2", "3"}}, "pair"},
+		}
+		for _, test := range tests {
+			var form testForm
+			err := URL.UnmarshalValues(test.src, &form)
+			var uerr *URL.UnmarshalError
+			if !errors.As(err, &uerr) {
+				t.Errorf("%v: expected *UnmarshalError, found %v", test.src, err)
+				continue
+			}
+			if uerr.Path != test.path {
+				t.Errorf("expected path %s, found %s", test.path, uerr.Path)
+			}
+		}
+	})
+
+	t.Run("container type", func(t *testing.T) {
+		var out struct {
+			T []int `url:"t"`
+		}
+		err := URL.UnmarshalValues(url.Values{"t[x]": {"1"}}, &out)
+		if err == nil || err.Error() != "t: cannot unmarshal into []int: Value is a ValueMap" {
+			t.Errorf("unexpected error %v", err)
+		}
+		mapV, _ := URL.ParseQuery("m[]=1")
+		var out2 struct {
+			M string `url:"m"`
+		}
+		if err := URL.Unmarshal(mapV, &out2); err == nil || !strings.Contains(err.Error(), "ValueSlice") {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		if err := URL.UnmarshalValues(url.Values{}, testForm{}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrRequired = errors.New("value required")
+
+// Validator is implemented by types checking their own content once unmarshaled, see Validate.
+//
+// Validate should return a *ValidationErrors or a *FieldError naming the bracket path of each invalid value,
+// other errors are reported at the root of the Map.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError reports an invalid value at a bracket path.
+type FieldError struct {
+	// Path is the bracket path of the value, ie "address[city]", empty for the whole Map
+	Path string
+	// Err describes why the value is invalid
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects the errors reported by Validate.
+//
+// errors.Is and errors.As inspect each of the collected errors.
+type ValidationErrors struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationErrors) Error() string {
+	switch len(e.Errors) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e.Errors[0], len(e.Errors)-1)
+}
+
+func (e *ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Validate checks the value pointed by v, usually filled by Unmarshal(m, v), and returns a *ValidationErrors if it is invalid.
+//
+// Struct fields tagged with the "required" option, `url:"email,required"`, must be present in m and must not be
+// ValueNil or an empty ValueString. Nested structs are checked only when present, each missing field is reported.
+// If every required field is present and v implements Validator, v.Validate() is called.
+//
+//	type Signup struct {
+//		Email   string   `url:"email,required"`
+//		Address *Address `url:"address"` // Address.City `url:"city,required"`
+//	}
+//
+//	// email=&address[zip]=00100
+//	err := url.Validate(mapV, &signup) // email: value required, address[city]: value required
+func Validate(m Map, v any) error {
+	return validate(m, v, nil)
+}
+
+// Bind stores m into the value pointed by v as Unmarshal does and checks it with Validate.
+//
+// Unlike Unmarshal, Bind does not stop at the first Value that cannot be stored, the bracket path of each of them
+// is reported in the returned *ValidationErrors along with the missing required fields, one error per path.
+// An empty value stored into a number results in the zero value, as HTML forms submit empty optional fields,
+// the "required" option reports it when the field is mandatory.
+//
+//	// age=x&height=y
+//	err := url.Bind(mapV, &signup) // age: cannot unmarshal into int ..., height: ..., email: value required
+func Bind(m Map, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Bind requires a non-nil pointer, found %T", v)
+	}
+	if m == nil {
+		return validate(m, v, nil)
+	}
+	root, err := m.GetValue()
+	if err != nil {
+		return err
+	}
+	d := decoder{collect: true, emptyAsZero: true}
+	if err := d.fail(d.value(root, rv.Elem())); err != nil {
+		return err
+	}
+	var errs []*FieldError
+	for _, uerr := range d.errs {
+		errs = append(errs, &FieldError{Path: uerr.Path, Err: fmt.Errorf("cannot unmarshal into %s: %w", uerr.Type, uerr.Err)})
+	}
+	return validate(m, v, errs)
+}
+
+// checks v as described by Validate, errs are reported before the missing required fields.
+func validate(m Map, v any, errs []*FieldError) error {
+	if m != nil && v != nil {
+		root, err := m.GetValue()
+		if err != nil {
+			return err
+		}
+		checkRequired(root, reflect.TypeOf(v), &errs, failed(errs))
+	}
+	if len(errs) > 0 {
+		return &ValidationErrors{Errors: errs}
+	}
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() {
+		if vr, ok := rv.Interface().(Validator); ok {
+			return validationErrors(vr.Validate())
+		}
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+	return nil
+}
+
+// converts the error returned by Validator.Validate() into a *ValidationErrors.
+func validationErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	var verrs *ValidationErrors
+	if errors.As(err, &verrs) {
+		return verrs
+	}
+	var ferr *FieldError
+	if errors.As(err, &ferr) {
+		return &ValidationErrors{Errors: []*FieldError{ferr}}
+	}
+	return &ValidationErrors{Errors: []*FieldError{{Err: err}}}
+}
+
+// returns the paths of errs.
+func failed(errs []*FieldError) map[string]bool {
+	paths := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		paths[err.Path] = true
+	}
+	return paths
+}
+
+// appends to errs the required fields of t missing from val, paths in skip and their elements are not checked.
+func checkRequired(val Value, t reflect.Type, errs *[]*FieldError, skip map[string]bool) {
+	if skip[val.Key()] {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == fileType {
+			return
+		}
+		m := val.ToMap()
+		if m == nil {
+			return
+		}
+		for _, f := range structFields(t) {
+			nested, ok := m[f.name]
+			if !ok || nested.IsNil() || (nested.Is(ValueString) && nested.Len() == 0) {
+				if f.required && !skip[childKey(val.Key(), f.name)] {
+					*errs = append(*errs, &FieldError{Path: childKey(val.Key(), f.name), Err: ErrRequired})
+				}
+				continue
+			}
+			checkRequired(nested, t.FieldByIndex(f.index).Type, errs, skip)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		for _, e := range entries(val, OrderSorted) {
+			checkRequired(e.value, t.Elem(), errs, skip)
+		}
+	}
+}
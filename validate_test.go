@@ -0,0 +1,162 @@
+package url_test
+
+import (
+	"errors"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+type testSignupAddress struct {
+	City string `url:"city,required"`
+	Zip  string `url:"zip"`
+}
+
+type testSignup struct {
+	Email     string              `url:"email,required"`
+	Name      string              `url:"name"`
+	Address   *testSignupAddress  `url:"address"`
+	Contacts  []testSignupAddress `url:"contacts"`
+	Agreement string              `url:"agreement"`
+}
+
+func (s *testSignup) Validate() error {
+	if s.Agreement != "on" {
+		return &URL.FieldError{Path: "agreement", Err: errors.New("must be accepted")}
+	}
+	return nil
+}
+
+func testValidationPaths(t *testing.T, err error) []string {
+	t.Helper()
+	var verrs *URL.ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected *ValidationErrors, found %v", err)
+	}
+	paths := make([]string, len(verrs.Errors))
+	for i, ferr := range verrs.Errors {
+		paths[i] = ferr.Path
+	}
+	return paths
+}
+
+func TestValidate(t *testing.T) {
+	validate := func(raw string) error {
+		t.Helper()
+		m, err := URL.ParseQuery(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var in testSignup
+		if err := URL.Unmarshal(m, &in); err != nil {
+			t.Fatal(err)
+		}
+		return URL.Validate(m, &in)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		if err := validate("email=a@b.c&address[city]=Rome&contacts[0][city]=Milan&agreement=on"); err != nil {
+			t.Errorf("expected no error, found %v", err)
+		}
+	})
+	t.Run("required", func(t *testing.T) {
+		err := validate("email=&address[zip]=00100&contacts[0][city]=Milan&contacts[1][zip]=1&agreement=on")
+		paths := testValidationPaths(t, err)
+		want := []string{"email", "address[city]", "contacts[1][city]"}
+		if len(paths) != len(want) {
+			t.Fatalf("expected %v, found %v", want, paths)
+		}
+		for i := range want {
+			if paths[i] != want[i] {
+				t.Errorf("expected %v, found %v", want, paths)
+			}
+		}
+		if !errors.Is(err, URL.ErrRequired) {
+			t.Error("expected errors.Is ErrRequired")
+		}
+	})
+	t.Run("missing nested struct", func(t *testing.T) {
+		if err := validate("email=a@b.c&agreement=on"); err != nil {
+			t.Errorf("expected no error, found %v", err)
+		}
+	})
+	t.Run("validator", func(t *testing.T) {
+		paths := testValidationPaths(t, validate("email=a@b.c"))
+		if len(paths) != 1 || paths[0] != "agreement" {
+			t.Errorf("expected [agreement], found %v", paths)
+		}
+	})
+	t.Run("plain error", func(t *testing.T) {
+		m, _ := URL.ParseQuery("")
+		var in testPlainValidator
+		paths := testValidationPaths(t, URL.Validate(m, &in))
+		if len(paths) != 1 || paths[0] != "" {
+			t.Errorf("expected [\"\"], found %v", paths)
+		}
+	})
+}
+
+type testPlainValidator struct{}
+
+func (testPlainValidator) Validate() error {
+	return errors.New("invalid")
+}
+
+func TestBind(t *testing.T) {
+	type input struct {
+		Age     int                `url:"age"`
+		Sizes   []int              `url:"sizes"`
+		Address *testSignupAddress `url:"address"`
+		Email   string             `url:"email,required"`
+	}
+	m, _ := URL.ParseQuery("age=x&sizes[]=1&sizes[]=y&address[zip]=1")
+	var in input
+	paths := testValidationPaths(t, URL.Bind(m, &in))
+	want := []string{"age", "sizes[1]", "address[city]", "email"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %v, found %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("expected %v, found %v", want, paths)
+		}
+	}
+	if in.Sizes[0] != 1 || in.Address.Zip != "1" {
+		t.Errorf("expected valid values to be stored, found %+v", in)
+	}
+	if err := URL.Bind(m, in); err == nil {
+		t.Error("expected an error for a non-pointer")
+	}
+
+	t.Run("one error per path", func(t *testing.T) {
+		type sub struct {
+			A int `url:"a,required"`
+		}
+		type input struct {
+			Sub  sub `url:"sub"`
+			Req  sub `url:"req,required"`
+			Page int `url:"page"`
+		}
+		m, _ := URL.ParseQuery("sub[a]=&req=x&page=")
+		var in input
+		paths := testValidationPaths(t, URL.Bind(m, &in))
+		if len(paths) != 2 || paths[0] != "req" || paths[1] != "sub[a]" {
+			t.Errorf("expected [req sub[a]], found %v", paths)
+		}
+	})
+	t.Run("empty optional number", func(t *testing.T) {
+		type input struct {
+			Page  int     `url:"page"`
+			Ratio float64 `url:"ratio"`
+			Ok    bool    `url:"ok"`
+		}
+		m, _ := URL.ParseQuery("page=&ratio=&ok=")
+		in := input{Page: 3, Ratio: 1, Ok: true}
+		if err := URL.Bind(m, &in); err != nil {
+			t.Fatalf("expected no error, found %v", err)
+		}
+		if in != (input{}) {
+			t.Errorf("expected zero values, found %+v", in)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package url
+
+import "errors"
+
+// SkipChildren is used as a return value from a WalkFunc to skip the elements of the Value being visited.
+// It has no effect when walking in post-order, as the elements have already been visited.
+var SkipChildren = errors.New("skip children")
+
+// StopWalk is used as a return value from a WalkFunc to stop the walk, Walk() then returns nil.
+var StopWalk = errors.New("stop walk")
+
+// WalkFunc is called by Walk() for each Value, path is relative to the Value Walk() was called on.
+// If WalkFunc returns an error other than SkipChildren or StopWalk the walk stops and Walk() returns it.
+type WalkFunc func(path Path, v Value) error
+
+// WalkOrder defines when a Value is visited relative to its elements.
+type WalkOrder int
+
+const (
+	// WalkPreOrder visits a Value before its elements, the default.
+	WalkPreOrder WalkOrder = iota
+	// WalkPostOrder visits a Value after its elements.
+	WalkPostOrder
+)
+
+// WalkOptions configures WalkWithOptions().
+type WalkOptions struct {
+	// Order selects pre-order or post-order traversal.
+	Order WalkOrder
+	// Keys is the order ValueMap keys are visited in, slices are always visited by index.
+	Keys KeyOrder
+}
+
+func (val *item) Walk(fn WalkFunc) error {
+	return val.WalkWithOptions(WalkOptions{}, fn)
+}
+
+func (val *item) WalkWithOptions(opts WalkOptions, fn WalkFunc) error {
+	err := walk(val, Path{}, opts, fn)
+	if err == StopWalk {
+		return nil
+	}
+	return err
+}
+
+// calls fn for each element of val and their descendants.
+func walk(val Value, path Path, opts WalkOptions, fn WalkFunc) error {
+	for _, e := range entries(val, opts.Keys) {
+		p := path.Append(e.key)
+		if opts.Order == WalkPreOrder {
+			if err := fn(p, e.value); err == SkipChildren {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+		if err := walk(e.value, p, opts, fn); err != nil {
+			return err
+		}
+		if opts.Order == WalkPostOrder {
+			if err := fn(p, e.value); err != nil && err != SkipChildren {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package url_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	URL "github.com/thetechpanda/url"
+)
+
+func TestWalk(t *testing.T) {
+	mapV, err := URL.ParseQuery("b[y]=1&b[x][]=2&b[x][]=3&a=4&c[k]=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(opts URL.WalkOptions, skip string) (paths []string, err error) {
+		err = mapV.WalkWithOptions(opts, func(path URL.Path, v URL.Value) error {
+			if path.String() != v.Key() {
+				t.Errorf("path %s does not match key %s", path, v.Key())
+			}
+			paths = append(paths, path.String())
+			if path.String() == skip {
+				return URL.SkipChildren
+			}
+			return nil
+		})
+		return
+	}
+
+	t.Run("pre-order", func(t *testing.T) {
+		paths, err := collect(URL.WalkOptions{}, "")
+		expect := []string{"a", "b", "b[x]", "b[x][0]", "b[x][1]", "b[y]", "c", "c[k]"}
+		if err != nil || !reflect.DeepEqual(paths, expect) {
+			t.Errorf("expected %v found %v (%v)", expect, paths, err)
+		}
+	})
+
+	t.Run("post-order", func(t *testing.T) {
+		paths, err := collect(URL.WalkOptions{Order: URL.WalkPostOrder}, "b")
+		expect := []string{"a", "b[x][0]", "b[x][1]", "b[x]", "b[y]", "b", "c[k]", "c"}
+		if err != nil || !reflect.DeepEqual(paths, expect) {
+			t.Errorf("expected %v found %v (%v)", expect, paths, err)
+		}
+	})
+
+	t.Run("insertion order", func(t *testing.T) {
+		paths, err := collect(URL.WalkOptions{Keys: URL.OrderInsertion}, "")
+		expect := []string{"b", "b[y]", "b[x]", "b[x][0]", "b[x][1]", "a", "c", "c[k]"}
+		if err != nil || !reflect.DeepEqual(paths, expect) {
+			t.Errorf("expected %v found %v (%v)", expect, paths, err)
+		}
+	})
+
+	t.Run("skip children", func(t *testing.T) {
+		paths, err := collect(URL.WalkOptions{}, "b")
+		expect := []string{"a", "b", "c", "c[k]"}
+		if err != nil || !reflect.DeepEqual(paths, expect) {
+			t.Errorf("expected %v found %v (%v)", expect, paths, err)
+		}
+	})
+
+	t.Run("stop and errors", func(t *testing.T) {
+		var visited int
+		err := mapV.Walk(func(path URL.Path, v URL.Value) error {
+			visited++
+			if path.String() == "b[x][0]" {
+				return URL.StopWalk
+			}
+			return nil
+		})
+		if err != nil || visited != 4 {
+			t.Errorf("StopWalk: expected 4 visits and no error, found %d (%v)", visited, err)
+		}
+
+		errFound := errors.New("found")
+		err = mapV.Walk(func(path URL.Path, v URL.Value) error {
+			if path.String() == "b[x][1]" {
+				return errFound
+			}
+			return nil
+		})
+		if err != errFound {
+			t.Errorf("expected the nested error to propagate, found %v", err)
+		}
+
+		var keys []string
+		err = mapV.Each(func(v URL.Value) error {
+			keys = append(keys, v.Key())
+			if v.Key() == "b[x][0]" {
+				return errFound
+			}
+			return nil
+		})
+		if err != errFound || !reflect.DeepEqual(keys, []string{"a", "b", "b[x]", "b[x][0]"}) {
+			t.Errorf("Each: unexpected %v (%v)", keys, err)
+		}
+	})
+}